@@ -0,0 +1,95 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+)
+
+// ABCD monthly water balance model
+// Thomas, H.A., 1981. Improved methods for national water assessment. Report WR15249270, US Water Resource Council, Washington, DC.
+// Alley, W.M., 1984. On the treatment of evapotranspiration, soil moisture accounting, and aquifer recharge in monthly water balance models. Water Resources Research 20(8). pp. 1137-1149.
+type ABCD struct {
+	s, gw      float64 // soil moisture and groundwater storage
+	a, b, c, d float64
+}
+
+// New ABCD constructor
+// [a, b, c, d]
+func (m *ABCD) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("ABCD", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if !ds.IsMonthly() {
+		return fmt.Errorf("ABCD requires monthly timesteps, see Dataset.ToMonthly")
+	}
+	if p[0] <= 0. || p[0] > 1. || p[1] <= 0. || fracCheck(p[2]) || fracCheck(p[3]) {
+		return &ParameterError{"ABCD", "a must be (0,1], b > 0, c and d must be [0,1]", p}
+	}
+	m.a = p[0] // propensity of runoff to occur before the soil is saturated
+	m.b = p[1] // upper limit of evapotranspiration and soil moisture storage
+	m.c = p[2] // fraction of excess water recharging groundwater
+	m.d = p[3] // groundwater discharge fraction
+	m.s, m.gw = 0., 0.
+	return nil
+}
+
+// Monthly marks ABCD as a monthly model
+func (m *ABCD) Monthly() {}
+
+// Update state
+func (m *ABCD) Update(p, ep float64) (float64, float64, float64) {
+	a, qd, qg, g := m.update(p, ep)
+	return a, qd + qg, g
+}
+
+// UpdateFluxes updates state, returning direct runoff (qd) and groundwater discharge (qg)
+func (m *ABCD) UpdateFluxes(p, ep float64) Fluxes {
+	a, qd, qg, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qd + qg, Recharge: g,
+		Q: map[string]float64{"qd": qd, "qg": qg},
+		S: map[string]float64{"s": m.s, "gw": m.gw},
+	}
+}
+
+func (m *ABCD) update(p, ep float64) (a, qd, qg, g float64) {
+	w := p + m.s // available water
+	wb := (w + m.b) / (2. * m.a)
+	y := wb - math.Sqrt(math.Max(wb*wb-w*m.b/m.a, 0.)) // evapotranspiration opportunity
+	m.s = y * math.Exp(-ep/m.b)
+	a = y - m.s
+	g = m.c * (w - y)
+	qd = w - y - g
+	m.gw = (m.gw + g) / (1. + m.d)
+	qg = m.d * m.gw
+	return
+}
+
+// Storage returns total storage
+func (m *ABCD) Storage() float64 {
+	return m.s + m.gw
+}
+
+// State returns the current state [s, gw]
+func (m *ABCD) State() State {
+	return State{S: []float64{m.s, m.gw}}
+}
+
+// SetState restores a state returned by State
+func (m *ABCD) SetState(s State) error {
+	if err := s.check("ABCD", 2, 0); err != nil {
+		return err
+	}
+	m.s, m.gw = s.S[0], s.S[1]
+	return nil
+}
+
+// Parameters describes the ABCD model parameters
+func (m *ABCD) Parameters() []Parameter {
+	return []Parameter{
+		{"a", "-", "propensity of runoff before soil saturation", 0., 1., .5, 1., false},
+		{"b", "m", "upper limit of evapotranspiration and soil moisture storage", 0., inf, .01, 1., true},
+		{"c", "-", "fraction of excess water recharging groundwater", 0., 1., 0., 1., false},
+		{"d", "-", "monthly groundwater discharge fraction", 0., 1., 0., 1., false},
+	}
+}
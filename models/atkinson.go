@@ -9,24 +9,33 @@ import (
 // original ref: Atkinson S.E., R.A. Woods, M. Sivapalan, 2002. Climate and landscape controls on water balance model complexity over changing timescales. Water Resource Research 38(12): 1314.
 // additional ref: Wittenberg H., M. Sivapalan, 1999. Watershed groundwater balance equation using streamflow recession analysis and baseflow separation. Journal of Hydrology 219, pp.20-33.
 // sto: current storage; sint current interception storage; cov: fractional forest cover; kb = 1/Tcbf
+// timesteps longer than an hour are divided into nsub hourly substeps; fsub: substep length [h]
 type Atkinson struct {
-	sto, sint, sintc, cov, kb, a, b, sbc, sfc float64
+	sto, sint, sintc, cov, kb, a, b, sbc, sfc, fsub float64
+	nsub                                            int
 }
 
 // New Atkinson constructor
 // [sbc, sfc, coverdense, intcap, kb, a, b]
-func (m *Atkinson) New(p ...float64) {
-	if p[4] < 0. || p[4] > 1. || p[6] < 0. || p[6] > 1. || p[0] < p[1] {
-		println(p[0], p[1], p[4], p[6])
-		panic("Atkinson input error")
+func (m *Atkinson) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("Atkinson", p, len(m.Parameters())); err != nil {
+		return err
 	}
-	m.sbc = p[0]           // A.1 - bucket capacity Sbc=D(n-tr)
-	m.sfc = p[1]           // A.2 & A.3 - threshold storage; originally written as  Sfc=Sbc*(fc-tr)/(n-tr)=D(fc-tr)
-	m.cov = p[2]           // fractional forest density
-	m.sintc = p[3]         // interception storage capacity
-	m.kb = p[4]            // baseflow recession coefficient
-	m.a = p[5]             // sub-surface flow coefficient (S=aQ^b - Wittenberg and Sivapalan, 1999)
-	m.b = 1. / (1. - p[6]) // sub-surface flow coefficient [0,1]; reciprocal taken here as opposed to in Update method
+	if p[4] < 0. || p[6] < 0. || p[6] > 1. || p[0] < p[1] {
+		return &ParameterError{"Atkinson", "kb >= 0, b must be [0,1], sbc >= sfc", p}
+	}
+	ts := ds.tsec()
+	m.nsub = int(math.Ceil(ts / 3600.))
+	dt := ts / float64(m.nsub) // substep length [s]
+	m.fsub = dt / 3600.
+	m.sbc = p[0]               // A.1 - bucket capacity Sbc=D(n-tr)
+	m.sfc = p[1]               // A.2 & A.3 - threshold storage; originally written as  Sfc=Sbc*(fc-tr)/(n-tr)=D(fc-tr)
+	m.cov = p[2]               // fractional forest density
+	m.sintc = p[3]             // interception storage capacity
+	m.kb = recession(p[4], dt) // baseflow recession coefficient [1/s] converted to substep
+	m.a = p[5]                 // sub-surface flow coefficient (S=aQ^b - Wittenberg and Sivapalan, 1999)
+	m.b = 1. / (1. - p[6])     // sub-surface flow coefficient [0,1]; reciprocal taken here as opposed to in Update method
+	return nil
 }
 
 // Storage returns total storage
@@ -34,11 +43,39 @@ func (m *Atkinson) Storage() float64 {
 	return m.sto + m.sint
 }
 
-// Update state for daily inputs
+// State returns the current state [sto, sint]
+func (m *Atkinson) State() State {
+	return State{S: []float64{m.sto, m.sint}}
+}
+
+// SetState restores a state returned by State
+func (m *Atkinson) SetState(s State) error {
+	if err := s.check("Atkinson", 2, 0); err != nil {
+		return err
+	}
+	m.sto = s.S[0]
+	m.sint = s.S[1]
+	return nil
+}
+
+// Parameters describes the Atkinson model parameters
+func (m *Atkinson) Parameters() []Parameter {
+	return []Parameter{
+		{"sbc", "m", "bucket capacity Sbc=D(n-tr); sampled as an excess over sfc", 0., inf, 0., .3, false},
+		{"sfc", "m", "threshold storage Sfc=D(fc-tr)", 0., inf, 0., .1, false},
+		{"coverdense", "-", "fractional forest density", 0., 1., 0., 1., false},
+		{"intcap", "m", "interception storage capacity", 0., inf, 0., .01, false},
+		{"kb", "1/s", "baseflow recession coefficient", 0., inf, 1e-9, 1e-3, true},
+		{"a", "-", "sub-surface flow coefficient (S=aQ^b - Wittenberg and Sivapalan, 1999), Q in m/h", 0., inf, 0., 100., false},
+		{"b", "-", "sub-surface flow exponent", 0., 1., 0., 1., false},
+	}
+}
+
+// Update state, substepping hourly
 func (m *Atkinson) Update(p, ep float64) (float64, float64, float64) {
 	var a, q, g float64
-	ph, eph := p/24., ep/24.
-	for i := 0; i < 24; i++ {
+	ph, eph := p/float64(m.nsub), ep/float64(m.nsub)
+	for i := 0; i < m.nsub; i++ {
 		a1, q1, g1 := m.UpdateHourly(ph, eph)
 		a += a1
 		q += q1
@@ -48,9 +85,33 @@ func (m *Atkinson) Update(p, ep float64) (float64, float64, float64) {
 	// return m.UpdateHourly(p, ep)
 }
 
-// UpdateHourly update state at the intended hourly interval
+// UpdateFluxes updates state, returning saturation excess (qse), subsurface runoff (qss) and baseflow (qbf)
+func (m *Atkinson) UpdateFluxes(p, ep float64) Fluxes {
+	var a, qse, qss, qbf, g float64
+	ph, eph := p/float64(m.nsub), ep/float64(m.nsub)
+	for i := 0; i < m.nsub; i++ {
+		a1, qse1, qss1, qbf1, g1 := m.updateHourly(ph, eph)
+		a += a1
+		qse += qse1
+		qss += qss1
+		qbf += qbf1
+		g += g1
+	}
+	return Fluxes{
+		AET: a, Runoff: qse + qss + qbf, Recharge: g,
+		Q: map[string]float64{"qse": qse, "qss": qss, "qbf": qbf},
+		S: map[string]float64{"sto": m.sto, "sint": m.sint},
+	}
+}
+
+// UpdateHourly update state at the intended hourly interval (or the model timestep, when shorter)
 func (m *Atkinson) UpdateHourly(p, ep float64) (float64, float64, float64) {
-	g := m.sto                           // saving antecedent storage
+	a, qse, qss, qbf, g := m.updateHourly(p, ep)
+	return a, qse + qss + qbf, g
+}
+
+func (m *Atkinson) updateHourly(p, ep float64) (a, qse, qss, qbf, g float64) {
+	g = m.sto                            // saving antecedent storage
 	eveg, ebs := m.cov*ep, (1.-m.cov)*ep // A.4 transpiration; A.5 bare soil evaporation
 	if m.sto < m.sfc {
 		eveg *= m.sto / m.sfc
@@ -58,21 +119,21 @@ func (m *Atkinson) UpdateHourly(p, ep float64) (float64, float64, float64) {
 	if m.sto < m.sbc {
 		ebs *= m.sto / m.sbc
 	}
-	var qse, qss float64 // A.6 saturation excess, A.7 subsurface runoff
+	// A.6 saturation excess, A.7 subsurface runoff
 	if m.sto > m.sbc {
 		qse = m.sto - m.sbc
-		qss = math.Pow((m.sbc-m.sfc)/m.a, m.b)
+		qss = math.Pow((m.sbc-m.sfc)/m.a, m.b) * m.fsub
 	} else if m.sto > m.sfc {
-		qss = math.Pow((m.sto-m.sfc)/m.a, m.b)
+		qss = math.Pow((m.sto-m.sfc)/m.a, m.b) * m.fsub
 	}
-	qbf := m.sto * m.kb // A.8 baseflow
-	eint := ep          // A.9 interception evaporation
+	qbf = m.sto * m.kb // A.8 baseflow
+	eint := ep         // A.9 interception evaporation
 	if p+m.sint < ep {
 		eint = p + m.sint
 	}
 
-	a := eveg + ebs + eint // A.10 total actual ET
-	var thr float64        // A.13 throughflow
+	a = eveg + ebs + eint // A.10 total actual ET
+	var thr float64       // A.13 throughflow
 	if p > (m.sintc - m.sint) {
 		thr = p - m.sintc + m.sint // modified from original
 	}
@@ -90,9 +151,8 @@ func (m *Atkinson) UpdateHourly(p, ep float64) (float64, float64, float64) {
 	// if g < 0. {
 	// 	g = 0. // not part of the Atkinson model, but closest assumption to infiltration (=recharge)
 	// }
-	q := qse + qss + qbf // total discharge
 
-	return a, q, g
+	return a, qse, qss, qbf, g
 }
 
 // // SampleSpace returns a hypercube from which the optimum resides
@@ -0,0 +1,103 @@
+package rainrun
+
+import "math"
+
+// AWBM Australian Water Balance Model
+// Boughton, W.C., 2004. The Australian water balance model. Environmental Modelling & Software 19. pp. 943-956.
+type AWBM struct {
+	sfc      [3]float64 // partial-area surface stores
+	c, a     [3]float64 // store capacities and partial areas
+	srf, bsf res        // surface routing and baseflow stores
+	bfi      float64
+}
+
+// New AWBM constructor
+// [c1, c2, c3, a1, a2, bfi, kb, ks]
+func (m *AWBM) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("AWBM", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if p[0] < 0. || p[1] < 0. || p[2] < 0. || fracCheck(p[3]) || fracCheck(p[4]) || p[3]+p[4] > 1. || fracCheck(p[5]) || p[6] < 0. || p[7] < 0. {
+		return &ParameterError{"AWBM", "capacities and recession rates must be >= 0, a1, a2 and bfi must be [0,1], a1+a2 <= 1", p}
+	}
+	ts := ds.tsec()
+	m.c = [3]float64{p[0], p[1], p[2]}
+	m.a = [3]float64{p[3], p[4], 1. - p[3] - p[4]} // a3: remaining partial area
+	m.bfi = p[5]                                   // baseflow index
+	m.bsf.new(math.MaxFloat64, recession(p[6], ts))
+	m.srf.new(math.MaxFloat64, recession(p[7], ts))
+	m.sfc = [3]float64{}
+	m.srf.sto, m.bsf.sto = 0., 0.
+	return nil
+}
+
+// Update state
+func (m *AWBM) Update(p, ep float64) (float64, float64, float64) {
+	a, qs, qb, g := m.update(p, ep)
+	return a, qs + qb, g
+}
+
+// UpdateFluxes updates state, returning surface runoff (qs) and baseflow (qb)
+func (m *AWBM) UpdateFluxes(p, ep float64) Fluxes {
+	a, qs, qb, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qs + qb, Recharge: g,
+		Q: map[string]float64{"qs": qs, "qb": qb},
+		S: map[string]float64{"s1": m.sfc[0], "s2": m.sfc[1], "s3": m.sfc[2], "srf": m.srf.sto, "bsf": m.bsf.sto},
+	}
+}
+
+func (m *AWBM) update(p, ep float64) (a, qs, qb, g float64) {
+	var x float64 // excess
+	for i := range m.sfc {
+		a += math.Min(ep, m.sfc[i]+p) * m.a[i]
+		m.sfc[i] = math.Max(m.sfc[i]+p-ep, 0.)
+		if m.sfc[i] > m.c[i] {
+			x += (m.sfc[i] - m.c[i]) * m.a[i]
+			m.sfc[i] = m.c[i]
+		}
+	}
+	g = m.bfi * x // recharge to the baseflow store
+	m.srf.update(x - g)
+	m.bsf.update(g)
+	qs, qb = m.srf.decayExp(), m.bsf.decayExp()
+	return
+}
+
+// Storage returns total storage
+func (m *AWBM) Storage() float64 {
+	s := m.srf.sto + m.bsf.sto
+	for i, v := range m.sfc {
+		s += v * m.a[i]
+	}
+	return s
+}
+
+// State returns the current state [s1, s2, s3, srf, bsf]
+func (m *AWBM) State() State {
+	return State{S: []float64{m.sfc[0], m.sfc[1], m.sfc[2], m.srf.sto, m.bsf.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *AWBM) SetState(s State) error {
+	if err := s.check("AWBM", 5, 0); err != nil {
+		return err
+	}
+	copy(m.sfc[:], s.S[:3])
+	m.srf.sto, m.bsf.sto = s.S[3], s.S[4]
+	return nil
+}
+
+// Parameters describes the AWBM parameters
+func (m *AWBM) Parameters() []Parameter {
+	return []Parameter{
+		{"c1", "m", "surface store 1 capacity", 0., inf, 0., .05, false},
+		{"c2", "m", "surface store 2 capacity", 0., inf, 0., .2, false},
+		{"c3", "m", "surface store 3 capacity", 0., inf, 0., .5, false},
+		{"a1", "-", "partial area of surface store 1", 0., 1., 0., .5, false},
+		{"a2", "-", "partial area of surface store 2", 0., 1., 0., .5, false},
+		{"bfi", "-", "baseflow index", 0., 1., 0., 1., false},
+		{"kb", "1/s", "baseflow recession rate", 0., inf, 1.2e-8, 1.2e-6, true},
+		{"ks", "1/s", "surface runoff recession rate", 0., inf, 1.2e-6, 2.7e-5, true},
+	}
+}
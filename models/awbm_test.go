@@ -0,0 +1,12 @@
+package rainrun
+
+import "testing"
+
+func TestAWBM(t *testing.T) {
+	p := []float64{.01, .05, .1, .2, .3, .4, 0., 1.} // [c1, c2, c3, a1, a2, bfi, kb, ks]
+	// stores [s1, s2, s3, srf, bsf]
+	checkSteps(t, func() Lumper { return &AWBM{} }, &Dataset{Timestep: secPerDay}, []step{
+		{"smallest store spills", p, State{}, .03, 0., 0., .02 * .2 * .6, .02 * .2 * .4, []float64{.01, .03, .03}},
+		{"partial area evaporation", p, State{S: []float64{.005, .02}}, 0., .01, .005*.2 + .01*.3, 0., 0., []float64{0., .01}},
+	})
+}
@@ -0,0 +1,105 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+)
+
+// Exchanger is implemented by models that gain or lose water other than through
+// P, AET and Q, such as deep losses or groundwater exchange. Recharge G is otherwise
+// taken to remain within the model's stores.
+type Exchanger interface {
+	Exchange() float64 // net water imported (>0) or exported (<0) during the last Update [m]
+}
+
+// Exchange returns the net external exchange of the last update of model m, 0 if m is not an Exchanger
+func Exchange(m interface{}) float64 {
+	if x, ok := m.(Exchanger); ok {
+		return x.Exchange()
+	}
+	return 0.
+}
+
+// Balance wraps a Lumper, verifying water balance closure at every update:
+//
+//	P + X - AET - Q = dS
+//
+// where X is the external exchange reported by an Exchanger.
+// The first step to exceed the tolerance is retained and reported by Err, such
+// that optimize and sample treat the parameter set as infeasible.
+type Balance struct {
+	Lumper
+	Tol             float64 // tolerance of a single timestep [m]
+	P, AET, Q, G, X float64 // cumulative fluxes
+	S0              float64 // initial storage
+	n               int
+	err             error
+}
+
+// NewBalance wraps model m in a mass-balance check of tolerance tol [m]
+func NewBalance(m Lumper, tol float64) *Balance {
+	b := Balance{Lumper: m, Tol: tol}
+	b.Reset()
+	return &b
+}
+
+// New builds the wrapped model and resets the balance
+func (b *Balance) New(ds *Dataset, p ...float64) error {
+	if err := b.Lumper.New(ds, p...); err != nil {
+		return err
+	}
+	b.Reset()
+	return nil
+}
+
+// SetState restores the wrapped model's state and resets the balance
+func (b *Balance) SetState(s State) error {
+	if err := b.Lumper.SetState(s); err != nil {
+		return err
+	}
+	b.Reset()
+	return nil
+}
+
+// Reset clears the cumulative fluxes, taking the current storage as initial
+func (b *Balance) Reset() {
+	b.P, b.AET, b.Q, b.G, b.X = 0., 0., 0., 0., 0.
+	b.S0 = b.Lumper.Storage()
+	b.n = 0
+	b.err = nil
+}
+
+// Update the wrapped model, checking its water balance
+func (b *Balance) Update(p, ep float64) (float64, float64, float64) {
+	s0 := b.Lumper.Storage()
+	a, q, g := b.Lumper.Update(p, ep)
+	x := Exchange(b.Lumper)
+	b.P += p
+	b.AET += a
+	b.Q += q
+	b.G += g
+	b.X += x
+	b.n++
+	e := p + x - a - q - (b.Lumper.Storage() - s0)
+	if b.err == nil && (math.Abs(e) > b.Tol || math.IsNaN(e)) {
+		b.err = &BalanceError{b.n, e}
+	}
+	return a, q, g
+}
+
+// Residual returns the cumulative water balance error [m]
+func (b *Balance) Residual() float64 {
+	return b.P + b.X - b.AET - b.Q - (b.Lumper.Storage() - b.S0)
+}
+
+// Err returns the wrapped model's numerical error, otherwise the first balance error
+func (b *Balance) Err() error {
+	if err := UpdateError(b.Lumper); err != nil {
+		return err
+	}
+	return b.err
+}
+
+func (b *Balance) String() string {
+	return fmt.Sprintf(" P: %.4f\tAET: %.4f\tQ: %.4f\tG: %.4f\tX: %.4f\tdS: %.4f\tresidual: %.3e\n", b.P, b.AET, b.Q, b.G, b.X, b.Lumper.Storage()-b.S0, b.Residual())
+}
@@ -0,0 +1,117 @@
+package rainrun
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// closes runs Lumper m with parameters p over dataset ds, reporting balance and numerical errors
+func closes(t *testing.T, m Lumper, ds *Dataset, p ...float64) *Balance {
+	t.Helper()
+	b := NewBalance(m, 1e-9)
+	if err := b.New(ds, p...); err != nil {
+		t.Fatalf("%T.New: %v", m, err)
+	}
+	for _, v := range ds.FRC {
+		b.Update(v[0], v[1])
+	}
+	if err := b.Err(); err != nil {
+		t.Errorf("%T: %v\n%v", m, err, b)
+	}
+	return b
+}
+
+// step is a hand-computed update of a Lumper
+type step struct {
+	name    string
+	p       []float64 // parameters
+	s0      State     // state preceding the update; stores and vectors not given are left as built by New
+	pr, ep  float64
+	a, q, g float64   // aet, runoff and recharge
+	s       []float64 // leading stores following the update, unchecked when nil
+}
+
+// checkSteps checks each hand-computed step of the Lumper returned by mnew over dataset ds, and its water balance
+func checkSteps(t *testing.T, mnew func() Lumper, ds *Dataset, cs []step) {
+	t.Helper()
+	for _, c := range cs {
+		m := mnew()
+		b := NewBalance(m, 1e-12)
+		if err := b.New(ds, c.p...); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		s := m.State()
+		copy(s.S, c.s0.S)
+		copy(s.V, c.s0.V)
+		if err := b.SetState(s); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		a, q, g := b.Update(c.pr, c.ep)
+		if math.Abs(a-c.a) > 1e-12 || math.Abs(q-c.q) > 1e-12 || math.Abs(g-c.g) > 1e-12 {
+			t.Errorf("%s: aet %g, q %g, g %g; want %g %g %g", c.name, a, q, g, c.a, c.q, c.g)
+		}
+		s1 := m.State().S
+		for i, v := range c.s {
+			if math.Abs(s1[i]-v) > 1e-12 {
+				t.Errorf("%s: stores %v; want %v", c.name, s1[:len(c.s)], c.s)
+				break
+			}
+		}
+		if err := b.Err(); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+	}
+}
+
+// leaky returns half its precipitation as runoff, holding nothing
+type leaky struct{}
+
+func (leaky) New(ds *Dataset, p ...float64) error              { return nil }
+func (leaky) Update(p, ep float64) (float64, float64, float64) { return 0., p / 2., 0. }
+func (leaky) Storage() float64                                 { return 0. }
+func (leaky) State() State                                     { return State{} }
+func (leaky) SetState(s State) error                           { return nil }
+
+func TestBalanceDetectsLeak(t *testing.T) {
+	b := NewBalance(leaky{}, 1e-9)
+	b.Update(0., .001)
+	if b.Err() != nil {
+		t.Fatalf("dry step: unexpected %v", b.Err())
+	}
+	b.Update(.01, .001)
+	var be *BalanceError
+	if !errors.As(b.Err(), &be) {
+		t.Fatalf("expected a BalanceError, got %v", b.Err())
+	}
+	if be.Step != 2 || math.Abs(be.Residual-.005) > 1e-12 {
+		t.Errorf("got step %d residual %g, want step 2 residual 0.005", be.Step, be.Residual)
+	}
+}
+
+func TestBalanceClosure(t *testing.T) {
+	for _, ts := range []float64{secPerDay, 3600.} {
+		ds := synthetic(400, ts)
+		ls := lumpers()
+		for _, l := range lumpers() {
+			if !strings.HasPrefix(l.name, "GR") {
+				continue
+			}
+			for _, x2 := range []float64{0., 2e-8} { // no exchange and imports
+				c := lumper{fmt.Sprintf("%s x2=%g", l.name, x2), l.new, l.params(), l.leaks}
+				c.p[1] = x2
+				ls = append(ls, c)
+			}
+		}
+		for _, l := range ls {
+			t.Run(fmt.Sprintf("%s/%.0fs", l.name, ts), func(t *testing.T) {
+				if l.leaks {
+					t.Skip("known to leak")
+				}
+				closes(t, l.new(), ds, l.params()...)
+			})
+		}
+	}
+}
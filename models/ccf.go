@@ -0,0 +1,77 @@
+package rainrun
+
+import "math"
+
+// coldContent snowpack, after the cold-content factor (CCF) model of DeWalle and Rango (2008) pg. 275-278.
+// The pack state is held explicitly so that it can be saved and restored.
+type coldContent struct {
+	tindex, ddf, ddfc, baseT, tsf float64 // parameters, scaled to the timestep
+	ice, liq, cc, ati             float64 // ice and liquid water content [m]; cold content [m]; antecedent temperature index [°C]
+}
+
+const lwhc = .05 // liquid water holding capacity, as a fraction of the ice content
+
+// newColdContent returns an empty snowpack; the daily temperature index, degree-day factor and
+// surface temperature factor are scaled to a timestep of fd days
+func newColdContent(tindex, ddf, ddfc, baseT, tsf, fd float64) coldContent {
+	return coldContent{
+		tindex: tindex * fd,
+		ddf:    ddf * fd,
+		ddfc:   ddfc,
+		baseT:  baseT,
+		tsf:    1. - math.Pow(1.-tsf, fd),
+	}
+}
+
+// update adds rainfall (r) and snowfall (s) at mean air temperature tm, returning the yield of the pack
+func (c *coldContent) update(r, s, tm float64) float64 {
+	c.ice += s
+	if c.ice <= 0. {
+		return r // no pack, rain passes through
+	}
+	c.liq += r
+
+	// cold content follows the difference between the antecedent (surface) temperature index and the air temperature
+	c.ati = math.Min(c.ati+c.tsf*(tm-c.ati), 0.)
+	c.cc = math.Max(c.cc+c.tindex*(c.ati-tm), 0.)
+
+	// liquid water refreezes, satisfying the cold content
+	fz := math.Min(c.liq, c.cc)
+	c.liq -= fz
+	c.ice += fz
+	c.cc -= fz
+
+	// melt potential first warms the pack, then melts ice
+	if tm > c.baseT {
+		mp := c.ddf * c.ddfc * (tm - c.baseT)
+		w := math.Min(mp, c.cc)
+		c.cc -= w
+		m := math.Min(mp-w, c.ice)
+		c.ice -= m
+		c.liq += m
+	}
+
+	if c.ice <= 0. { // pack is depleted
+		y := c.liq
+		c.ice, c.liq, c.cc, c.ati = 0., 0., 0., 0.
+		return y
+	}
+	y := math.Max(c.liq-lwhc*c.ice, 0.)
+	c.liq -= y
+	return y
+}
+
+// swe returns the snowpack water content (ice plus liquid water)
+func (c *coldContent) swe() float64 {
+	return c.ice + c.liq
+}
+
+// state returns the pack state: [swe, liquid water, cold content, antecedent temperature index]
+func (c *coldContent) state() []float64 {
+	return []float64{c.ice + c.liq, c.liq, c.cc, c.ati}
+}
+
+// setState restores a pack state returned by state
+func (c *coldContent) setState(s []float64) {
+	c.ice, c.liq, c.cc, c.ati = s[0]-s[1], s[1], s[2], s[3]
+}
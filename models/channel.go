@@ -0,0 +1,46 @@
+package rainrun
+
+import (
+	"fmt"
+
+	"github.com/maseology/rainrun/routing"
+)
+
+// Link is a catchment model draining through a channel reach to the next link downstream
+type Link struct {
+	M     Model              // a Lumper or a Climater, already constructed with New
+	DS    *Dataset           // catchment forcings
+	Area  float64            // catchment area [m²]
+	Reach *routing.Muskingum // reach to the next link, nil for none (i.e., the outlet)
+}
+
+// Chain of catchments ordered from upstream to downstream, run as a linear Network
+type Chain struct {
+	Links []Link
+}
+
+// Network returns the chain as a linear network, link i being node i draining to node i+1
+func (c *Chain) Network() (*Network, error) {
+	nodes := make([]Node, len(c.Links))
+	for i, l := range c.Links {
+		nodes[i] = Node{ID: i, M: l.M, DS: l.DS, Area: l.Area, Dn: i + 1, Reach: l.Reach}
+	}
+	if n := len(nodes); n > 0 {
+		nodes[n-1].Dn = -1
+	}
+	return NewNetwork(nodes)
+}
+
+// Run the chain, returning the discharge [m³/s] at each link, per timestep (see Network.Run)
+func (c *Chain) Run() ([][]float64, error) {
+	nw, err := c.Network()
+	if err != nil {
+		return nil, fmt.Errorf("Chain.Run error: %v", err)
+	}
+	o, err := nw.Run()
+	q := make([][]float64, len(c.Links))
+	for i := range q {
+		q[i] = o[i]
+	}
+	return q, err
+}
@@ -0,0 +1,54 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+
+	"github.com/maseology/rainrun/routing"
+)
+
+func TestChain(t *testing.T) {
+	ds, p := synthetic(200, secPerDay), mid((&GR4J{}).Parameters())
+	gr4j := func() *GR4J {
+		m := &GR4J{}
+		if err := m.New(ds, p...); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	reach := func() *routing.Muskingum {
+		r, err := routing.NewMuskingum(7200., .2, secPerDay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	a0, a1 := 2e7, 5e7
+	c := Chain{Links: []Link{{gr4j(), ds, a0, reach()}, {gr4j(), ds, a1, nil}}}
+	q, err := c.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m0, m1, r := gr4j(), gr4j(), reach()
+	for k, v := range ds.FRC {
+		_, q0, _ := m0.Update(v[0], v[1])
+		_, q1, _ := m1.Update(v[0], v[1])
+		want := [2]float64{q0 * a0 / secPerDay, 0.}
+		want[1] = q1*a1/secPerDay + r.Update(want[0])
+		if math.Abs(q[0][k]-want[0]) > 1e-9 || math.Abs(q[1][k]-want[1]) > 1e-9 {
+			t.Fatalf("step %d: got %g %g, want %v", k+1, q[0][k], q[1][k], want)
+		}
+	}
+
+	if _, err := (&Chain{}).Run(); err == nil {
+		t.Error("expected an error for an empty chain")
+	}
+	hr, err := routing.NewMuskingum(7200., .2, 3600.)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&Chain{Links: []Link{{gr4j(), ds, a0, hr}, {gr4j(), ds, a1, nil}}}).Run(); err == nil {
+		t.Error("expected an error for a reach routed at another timestep")
+	}
+}
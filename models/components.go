@@ -0,0 +1,138 @@
+package rainrun
+
+import "math"
+
+// FluxInput is the state available to a flux equation over a timestep
+type FluxInput struct {
+	Supply float64 // water available at the flux source: the remaining rainfall, or the source store
+	S, Cap float64 // storage and capacity of the controlling store
+	Ep     float64 // remaining evaporative demand
+	Ts     float64 // timestep [s]
+}
+
+// frac returns the controlling store's storage fraction
+func (in FluxInput) frac() float64 {
+	if in.Cap <= 0. || math.IsInf(in.Cap, 1) {
+		return 0.
+	}
+	return math.Min(in.S/in.Cap, 1.)
+}
+
+// FluxEq is a flux equation of the modular component library, returning the flux over
+// a timestep given its input and parameters p, ordered as described by Parameters.
+// Returned fluxes are limited to the supply by the caller.
+type FluxEq interface {
+	Describer
+	Flux(in FluxInput, p []float64) float64
+}
+
+// Bounded is implemented by flux equations of the controlling store's capacity, which
+// must be controlled by a store of finite capacity
+type Bounded interface {
+	FluxEq
+	Bounded()
+}
+
+// Linear drainage of the supply: q = (1-exp(-k·ts))·supply
+type Linear struct{}
+
+// Flux returns the linear reservoir outflow
+func (Linear) Flux(in FluxInput, p []float64) float64 { return recession(p[0], in.Ts) * in.Supply }
+
+// Parameters describes the Linear flux parameters
+func (Linear) Parameters() []Parameter {
+	return []Parameter{{"k", "1/s", "recession rate", 0., inf, 1e-8, 1e-5, true}}
+}
+
+// Power drainage of the controlling store (e.g., PDM, ARNO/VIC baseflow): q = k·ts·cap·(s/cap)^n
+type Power struct{}
+
+// Flux returns the non-linear reservoir outflow
+func (Power) Flux(in FluxInput, p []float64) float64 {
+	return p[0] * in.Ts * in.Cap * math.Pow(in.frac(), p[1])
+}
+
+// Bounded marks Power as capacity dependent
+func (Power) Bounded() {}
+
+// Parameters describes the Power flux parameters
+func (Power) Parameters() []Parameter {
+	return []Parameter{
+		{"k", "1/s", "drainage rate at capacity", 0., inf, 1e-8, 1e-5, true},
+		{"n", "-", "drainage exponent", 0., inf, 1., 5., false},
+	}
+}
+
+// Overflow of the controlling store in excess of its capacity
+type Overflow struct{}
+
+// Flux returns the storage in excess of capacity
+func (Overflow) Flux(in FluxInput, p []float64) float64 { return math.Max(in.S-in.Cap, 0.) }
+
+// Bounded marks Overflow as capacity dependent
+func (Overflow) Bounded() {}
+
+// Parameters describes the Overflow flux parameters (none)
+func (Overflow) Parameters() []Parameter { return nil }
+
+// Evaporation from the controlling store, at the remaining demand above lp·cap, reducing linearly below (e.g., HBV)
+type Evaporation struct{}
+
+// Flux returns the actual evaporation
+func (Evaporation) Flux(in FluxInput, p []float64) float64 {
+	if p[0] <= 0. {
+		return in.Ep
+	}
+	return in.Ep * math.Min(in.frac()/p[0], 1.)
+}
+
+// Bounded marks Evaporation as capacity dependent
+func (Evaporation) Bounded() {}
+
+// Parameters describes the Evaporation flux parameters
+func (Evaporation) Parameters() []Parameter {
+	return []Parameter{{"lp", "-", "storage fraction above which evaporation is potential", 0., 1., .3, 1., false}}
+}
+
+// Pareto saturated-area fraction of the supply, given the controlling store (ARNO/VIC, HYMOD): q = supply·(1-(1-s/cap)^b)
+type Pareto struct{}
+
+// Flux returns the saturation excess of the supply
+func (Pareto) Flux(in FluxInput, p []float64) float64 {
+	return in.Supply * (1. - math.Pow(1.-in.frac(), p[0]))
+}
+
+// Bounded marks Pareto as capacity dependent
+func (Pareto) Bounded() {}
+
+// Parameters describes the Pareto flux parameters
+func (Pareto) Parameters() []Parameter {
+	return []Parameter{{"b", "-", "saturated area shape parameter", 0., inf, .01, 3., false}}
+}
+
+// Beta contributing fraction of the supply, given the controlling store (HBV): q = supply·(s/cap)^beta
+type Beta struct{}
+
+// Flux returns the contributing fraction of the supply
+func (Beta) Flux(in FluxInput, p []float64) float64 {
+	return in.Supply * math.Pow(in.frac(), p[0])
+}
+
+// Bounded marks Beta as capacity dependent
+func (Beta) Bounded() {}
+
+// Parameters describes the Beta flux parameters
+func (Beta) Parameters() []Parameter {
+	return []Parameter{{"beta", "-", "shape coefficient", 0., inf, .5, 6., false}}
+}
+
+// Split diverts a fixed fraction of the supply
+type Split struct{}
+
+// Flux returns the diverted fraction of the supply
+func (Split) Flux(in FluxInput, p []float64) float64 { return p[0] * in.Supply }
+
+// Parameters describes the Split flux parameters
+func (Split) Parameters() []Parameter {
+	return []Parameter{{"f", "-", "fraction diverted", 0., 1., 0., 1., false}}
+}
@@ -6,7 +6,7 @@ import (
 
 // DawdyODonnell model
 // ref: Dawdy, D.R., and T. O'Donnell, 1965. Mathematical Models of Catchment Behavior. Journal of Hydraulics Division, ASCE, Vol. 91, No. HY4, pp. 123-137.
-//see:  pg.34 in Dooge and O'Kane (2003)
+// see:  pg.34 in Dooge and O'Kane (2003)
 type DawdyODonnell struct {
 	depint, upsz manabe
 	ores, gwres  res // S, G
@@ -15,19 +15,43 @@ type DawdyODonnell struct {
 
 // New DawdyODonnell constructor
 // [ksat, depintCap, upszCap, gwCap, olfk, bfk]
-func (m *DawdyODonnell) New(p ...float64) {
+func (m *DawdyODonnell) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("DawdyODonnell", p, len(m.Parameters())); err != nil {
+		return err
+	}
 	if p[0] < 0. {
-		panic("DawdyODonnell error, ksat < 0.0")
+		return &ParameterError{"DawdyODonnell", "ksat < 0.0", p}
+	}
+	ts := ds.tsec()
+	m.ksat = p[0] * ts                                 // [m/s] to [m/ts]
+	if err := m.depint.new(p[1], 1., 0.); err != nil { // R; depintCap = R*
+		return err
 	}
-	m.ksat = p[0]
-	m.depint.new(p[1], 1., 0.)            // R; depintCap = R*
-	m.ores.new(math.MaxFloat64, p[4])     // S; overland flow recession coefficient
-	m.upsz.new(math.MaxFloat64, 1., p[2]) // M; upszCap = M*
-	m.gwres.new(p[3], p[5])               // G; gwCap = G*; baseflow recession coefficient
+	m.ores.new(math.MaxFloat64, recession(p[4], ts))              // S; overland flow recession coefficient
+	if err := m.upsz.new(math.MaxFloat64, 1., p[2]); err != nil { // M; upszCap = M*
+		return err
+	}
+	m.gwres.new(p[3], recession(p[5], ts)) // G; gwCap = G*; baseflow recession coefficient
+	return nil
 }
 
-// Update state for daily inputs
+// Update state
 func (m *DawdyODonnell) Update(p, ep float64) (float64, float64, float64) {
+	a, qs, qb, g := m.update(p, ep)
+	return a, qs + qb, g
+}
+
+// UpdateFluxes updates state, returning overland flow (qs) and baseflow (qb)
+func (m *DawdyODonnell) UpdateFluxes(p, ep float64) Fluxes {
+	a, qs, qb, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qs + qb, Recharge: g,
+		Q: map[string]float64{"qs": qs, "qb": qb},
+		S: map[string]float64{"R": m.depint.sto, "M": m.upsz.sto, "S": m.ores.sto, "G": m.gwres.sto},
+	}
+}
+
+func (m *DawdyODonnell) update(p, ep float64) (a, qs, qb, g float64) {
 	// fill depressions & interception (R)
 	eR, q1, f := m.depint.update(p, ep, m.ksat) // set percolation rate (F) to vertical conductivity, and overflow (Q1) to S
 	m.ores.update(q1)                           // to overland flow stor (S)
@@ -37,10 +61,10 @@ func (m *DawdyODonnell) Update(p, ep float64) (float64, float64, float64) {
 	c := m.gwres.update(d)                   // (C)
 	eM, _, _ := m.upsz.update(c, ep-eR, 0.0) // add lower overflow back to upper soil zone (C)
 	// total flow, AET, recharge
-	q := m.gwres.decayExp() + m.ores.decayExp() // Qt = Qb + Qs
-	a := eM + eR                                // total ET = EM + ER
-	g := c - d                                  // net recharge
-	return a, q, g
+	qb, qs = m.gwres.decayExp(), m.ores.decayExp() // Qt = Qb + Qs
+	a = eM + eR                                    // total ET = EM + ER
+	g = c - d                                      // net recharge
+	return
 }
 
 // Storage returns total storage
@@ -48,6 +72,35 @@ func (m *DawdyODonnell) Storage() float64 {
 	return m.depint.sto + m.upsz.sto + m.ores.sto + m.gwres.sto
 }
 
+// State returns the current state [R, M, S, G]
+func (m *DawdyODonnell) State() State {
+	return State{S: []float64{m.depint.sto, m.upsz.sto, m.ores.sto, m.gwres.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *DawdyODonnell) SetState(s State) error {
+	if err := s.check("DawdyODonnell", 4, 0); err != nil {
+		return err
+	}
+	m.depint.sto = s.S[0]
+	m.upsz.sto = s.S[1]
+	m.ores.sto = s.S[2]
+	m.gwres.sto = s.S[3]
+	return nil
+}
+
+// Parameters describes the DawdyODonnell model parameters
+func (m *DawdyODonnell) Parameters() []Parameter {
+	return []Parameter{
+		{"ksat", "m/s", "vertical conductivity", 0., inf, 1e-12, 1., true},
+		{"depintCap", "m", "depression and interception capacity R*", 0., inf, 0., .1, false},
+		{"upszCap", "m", "upper soil zone capacity M*", 0., inf, 0., 1000., false},
+		{"gwCap", "m", "lower soil zone capacity G*", 0., inf, 0., 1000., false},
+		{"olfk", "1/s", "overland flow recession coefficient", 0., inf, 1e-10, 1e-4, true},
+		{"bfk", "1/s", "baseflow recession coefficient", 0., inf, 1e-10, 1e-4, true},
+	}
+}
+
 // // SampleSpace returns a hypercube from which the optimum resides
 // func (m *DawdyODonnell) SampleSpace(u []float64) []float64 {
 // 	ksat := mm.LogLinearTransform(1e-12, 1., u[0]) // ksat [m/s]
@@ -0,0 +1,72 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/maseology/mmaths"
+)
+
+// Parameter describes a model parameter: its meaning, units, physical bounds and default sampling range
+type Parameter struct {
+	Name, Unit, Desc string
+	Min, Max         float64 // physical bounds
+	Lo, Hi           float64 // default sampling range
+	Log              bool    // sampled log-linearly
+}
+
+// Describer is implemented by models that describe their parameters, in the order expected by New
+type Describer interface {
+	Parameters() []Parameter
+}
+
+// Sample maps a uniform sample u [0,1] onto the parameter's default sampling range
+func (p Parameter) Sample(u float64) float64 {
+	if p.Log {
+		return mmaths.LogLinearTransform(p.Lo, p.Hi, u)
+	}
+	return mmaths.LinearTransform(p.Lo, p.Hi, u)
+}
+
+// Names returns the parameter names
+func Names(ps []Parameter) []string {
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = p.Name
+	}
+	return s
+}
+
+// checkBounds returns a ParameterError when p does not hold one value within the physical bounds of each parameter ps
+func checkBounds(model string, ps []Parameter, p []float64) error {
+	if err := checkCount(model, p, len(ps)); err != nil {
+		return err
+	}
+	for i, par := range ps {
+		if !(p[i] >= par.Min && p[i] <= par.Max) {
+			return &ParameterError{model, fmt.Sprintf("%s must be [%g,%g]", par.Name, par.Min, par.Max), p}
+		}
+	}
+	return nil
+}
+
+var inf = math.Inf(1)
+
+// CCFParameters describes the cold-content snowmelt parameters
+func CCFParameters() []Parameter {
+	return []Parameter{
+		{"tindex", "m/°C/d", "CCF temperature index; roughly 1/10 DDF (pg.278)", 0., inf, .0002, .05, true},
+		{"ddfc", "-", "DDF adjustment factor based on pack density, see DeWalle and Rango, pg. 275; Ref: Martinec (1960)=1.1", 0., inf, 0., 10., false},
+		{"baseT", "°C", "base/critical temperature", -inf, inf, -5., 5., false},
+		{"tsf", "-", "TSF (surface temperature factor), 0.1-0.5 have been used", 0., 1., .1, .7, false},
+		{"ddf", "m/°C/d", "degree-day factor", 0., inf, .001, .008, false},
+	}
+}
+
+// MakkinkParameters describes the Makkink PET coefficients
+func MakkinkParameters() []Parameter {
+	return []Parameter{
+		{"alpha", "-", "Makkink alpha coefficient", 0., inf, 0., 2.5, false},
+		{"beta", "m/d", "Makkink beta coefficient", -inf, inf, -.01, .003, false},
+	}
+}
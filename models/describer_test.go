@@ -0,0 +1,53 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParameters(t *testing.T) {
+	ds := []Describer{}
+	for _, l := range lumpers() {
+		ds = append(ds, l.new().(Describer))
+	}
+	for _, d := range []Describer{&CCFGR4J{}, &CCFHBV{}, &MakkinkCCFGR4J{}, &CCFSacramento{}, &GR2M{}, &ABCD{}} {
+		ds = append(ds, d)
+	}
+	for _, f := range snows {
+		ds = append(ds, f())
+	}
+	for _, f := range pets {
+		ds = append(ds, f())
+	}
+	for _, f := range uhs {
+		ds = append(ds, f())
+	}
+	for _, d := range ds {
+		ps := d.Parameters()
+		if len(ps) == 0 {
+			t.Errorf("%T: no parameters", d)
+		}
+		for _, p := range ps {
+			if p.Name == "" || p.Min > p.Lo || p.Lo >= p.Hi || p.Hi > p.Max || (p.Log && p.Lo <= 0.) {
+				t.Errorf("%T %s: inconsistent bounds %g <= [%g, %g] <= %g, log %v", d, p.Name, p.Min, p.Lo, p.Hi, p.Max, p.Log)
+			}
+		}
+	}
+}
+
+func TestParameterSample(t *testing.T) {
+	for _, c := range []struct {
+		p         Parameter
+		u, sample float64
+	}{
+		{Parameter{Lo: 1., Hi: 3.}, 0., 1.},
+		{Parameter{Lo: 1., Hi: 3.}, .5, 2.},
+		{Parameter{Lo: 1., Hi: 3.}, 1., 3.},
+		{Parameter{Lo: 1e-6, Hi: 1e-2, Log: true}, .5, 1e-4},
+		{Parameter{Lo: 1e-6, Hi: 1e-2, Log: true}, 1., 1e-2},
+	} {
+		if s := c.p.Sample(c.u); math.Abs(s-c.sample) > 1e-9*c.sample {
+			t.Errorf("%+v: Sample(%g) = %g, want %g", c.p, c.u, s, c.sample)
+		}
+	}
+}
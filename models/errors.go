@@ -0,0 +1,56 @@
+package rainrun
+
+import "fmt"
+
+// ParameterError is returned by a model constructor when given an infeasible parameter set
+type ParameterError struct {
+	Model string    // model name
+	Msg   string    // constraint that was violated
+	P     []float64 // parameter set
+}
+
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("%s input error: %s %v", e.Model, e.Msg, e.P)
+}
+
+// checkCount returns a ParameterError when p does not hold the n parameters of model
+func checkCount(model string, p []float64, n int) error {
+	if len(p) != n {
+		return &ParameterError{model, fmt.Sprintf("expecting %d parameters", n), p}
+	}
+	return nil
+}
+
+// NumericalError is raised by a model's state update when it fails numerically
+type NumericalError struct {
+	Model string // model name
+	Msg   string // description of the failure
+}
+
+func (e *NumericalError) Error() string {
+	return fmt.Sprintf("%s error: %s", e.Model, e.Msg)
+}
+
+// Failer is implemented by models that can report numerical failures raised during Update.
+// The first failure is retained; subsequent output should be considered invalid.
+type Failer interface {
+	Err() error
+}
+
+// UpdateError returns the numerical error raised by model m, nil if none has occurred or m is not a Failer
+func UpdateError(m interface{}) error {
+	if f, ok := m.(Failer); ok {
+		return f.Err()
+	}
+	return nil
+}
+
+// BalanceError reports a water balance that failed to close within tolerance
+type BalanceError struct {
+	Step     int     // timestep at which the balance first failed
+	Residual float64 // P + X - AET - Q - dS [m]
+}
+
+func (e *BalanceError) Error() string {
+	return fmt.Sprintf("mass balance error at step %d: residual %.3e", e.Step, e.Residual)
+}
@@ -0,0 +1,62 @@
+package rainrun
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParameterError(t *testing.T) {
+	ls := make(map[string]lumper)
+	for _, l := range lumpers() {
+		ls[l.name] = l
+	}
+	ds := synthetic(10, secPerDay)
+	for _, c := range []struct {
+		name string
+		i    int     // parameter index
+		v    float64 // infeasible value
+	}{
+		{"Atkinson", 4, -1.},
+		{"DawdyODonnell", 0, -1.},
+		{"GR4J", 0, 0.},
+		{"GR4J", 2, 0.},
+		{"GR4J", 3, .1},
+		{"HBV", 1, 1.5},
+		{"ManabeGW", 0, -1.},
+		{"MultiLayerCapacitance", 6, .9},
+		{"Quinn", 3, 2.},
+		{"Sacramento", 0, 0.},
+	} {
+		l := ls[c.name]
+		p := l.params()
+		p[c.i] = c.v
+		var pe *ParameterError
+		if err := l.new().New(ds, p...); !errors.As(err, &pe) {
+			t.Errorf("%s: p[%d]=%g returned %v, want a ParameterError", c.name, c.i, c.v, err)
+		}
+	}
+}
+
+func TestParameterCount(t *testing.T) {
+	ds := synthetic(10, secPerDay)
+	for _, l := range lumpers() {
+		p := l.params()
+		var pe *ParameterError
+		if err := l.new().New(ds, p[:len(p)-1]...); !errors.As(err, &pe) {
+			t.Errorf("%s: %d parameters returned %v, want a ParameterError", l.name, len(p)-1, err)
+		}
+	}
+}
+
+func TestNumericalError(t *testing.T) {
+	m := &GR4J{name: "GR4J"}
+	if UpdateError(m) != nil || UpdateError(&SPLR{}) != nil {
+		t.Fatal("unexpected error before failure")
+	}
+	m.fail("first")
+	m.fail("second")
+	var ne *NumericalError
+	if err := UpdateError(m); !errors.As(err, &ne) || ne.Msg != "first" {
+		t.Errorf("got %v, want the first NumericalError", err)
+	}
+}
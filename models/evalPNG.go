@@ -7,32 +7,41 @@ import (
 	"github.com/maseology/objfunc"
 )
 
-// EvalPNG prints model output to a png
-func EvalPNG(m Lumper) string {
-	o := make([]float64, Ndt)
-	s := make([]float64, Ndt)
-	b := make([]float64, Ndt)
+// EvalPNG prints model output to a png; m is either a Lumper or a Climater
+func EvalPNG(ds *Dataset, m Model) string {
+	o := make([]float64, ds.Ndt)
+	s := make([]float64, ds.Ndt)
+	b := make([]float64, ds.Ndt)
+	_, lumped := m.(Lumper)
 	ys, es, as, rs, gs, qs := 0., 0., 0., 0., 0., 0.
-	for i, v := range FRC {
-		a, r, g := m.Update(v[0], v[1])
-		o[i] = v[2]
+	for i, v := range ds.FRC {
+		y, a, r, g := Step(ds, m, i)
+		o[i] = ds.Obs(i)
 		s[i] = r
 		b[i] = g
-		ys += v[0]
-		es += v[1]
+		ys += y
+		if lumped {
+			es += v[1]
+		}
 		as += a
 		rs += r
 		gs += g
-		qs += v[2]
+		qs += o[i]
+	}
+	if err := UpdateError(m); err != nil {
+		fmt.Printf(" warning: %v\n", err)
+	}
+	f, w := ds.stepsPerYear()/float64(ds.Ndt), ds.Warmup() // annual rates; first year discarded
+	stOf := fmt.Sprintf(" KGE: %.3f\tNSE: %.3f\tRMSE: %.6f\tmon-wr2: %.3f\tBias: %.3f\n", objfunc.KGE(o[w:], s[w:]), objfunc.NSE(o[w:], s[w:]), objfunc.RMSE(o[w:], s[w:]), objfunc.Krause(o[w:], s[w:]), objfunc.Bias(o[w:], s[w:]))
+	stSum := fmt.Sprintf(" y: %.3f\taet: %.3f\trch: %.3f\tro: %.3f\tqobs: %.3f\n", ys*f, as*f, gs*f, rs*f, qs*f)
+	if lumped {
+		stSum = fmt.Sprintf(" y: %.3f\tpet: %.3f\taet: %.3f\trch: %.3f\tro: %.3f\tqobs: %.3f\n", ys*f, es*f, as*f, gs*f, rs*f, qs*f)
 	}
-	f := 366. / float64(Ndt)
-	stOf := fmt.Sprintf(" KGE: %.3f\tNSE: %.3f\tRMSE: %.6f\tmon-wr2: %.3f\tBias: %.3f\n", objfunc.KGE(o[365:], s[365:]), objfunc.NSE(o[365:], s[365:]), objfunc.RMSE(o[365:], s[365:]), objfunc.Krause(o[365:], s[365:]), objfunc.Bias(o[365:], s[365:]))
-	stSum := fmt.Sprintf(" y: %.3f\tpet: %.3f\taet: %.3f\trch: %.3f\tro: %.3f\tqobs: %.3f\n", ys*f, es*f, as*f, gs*f, rs*f, qs*f)
 	fmt.Print(stOf)
 	fmt.Print(stSum)
-	mmplt.ObsSim("hyd.png", o[365:], s[365:])
-	mmplt.ObsSimFDC("fdc.png", o[365:], s[365:])
-	SumHydrograph(o, s, b)
-	SumMonthly(DT, o, s, Timestep, 1.)
+	mmplt.ObsSim("hyd.png", o[w:], s[w:])
+	mmplt.ObsSimFDC("fdc.png", o[w:], s[w:])
+	SumHydrograph(ds, o, s, b)
+	SumMonthly(ds.DT, o, s, ds.Timestep, 1.)
 	return stOf + stSum
 }
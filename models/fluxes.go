@@ -0,0 +1,72 @@
+package rainrun
+
+import (
+	"sort"
+	"time"
+
+	"github.com/maseology/mmio"
+)
+
+// Fluxes holds the output of a single model update: the totals returned by
+// Update, the named flux components that make them up and the storage of
+// every store following the update.
+type Fluxes struct {
+	AET, Runoff, Recharge float64
+	Q                     map[string]float64 // named flux components
+	S                     map[string]float64 // named storages
+}
+
+// Fluxer is implemented by models that report their flux components and storages
+type Fluxer interface {
+	UpdateFluxes(p, ep float64) Fluxes
+}
+
+// SimulateFluxes runs a model over the dataset, returning the fluxes of every timestep
+func SimulateFluxes(ds *Dataset, m Fluxer) []Fluxes {
+	fs := make([]Fluxes, ds.Ndt)
+	for i, v := range ds.FRC {
+		fs[i] = m.UpdateFluxes(v[0], v[1])
+	}
+	return fs
+}
+
+// WriteFluxes saves a flux time series to csv: totals, then flux components and storages in alphabetical order
+func WriteFluxes(fp string, dt []time.Time, fs []Fluxes) {
+	if len(fs) == 0 {
+		return
+	}
+	keys := func(m map[string]float64) []string {
+		s := make([]string, 0, len(m))
+		for k := range m {
+			s = append(s, k)
+		}
+		sort.Strings(s)
+		return s
+	}
+	qk, sk := keys(fs[0].Q), keys(fs[0].S)
+	n := len(fs)
+	hdr := "date,aet,ro,rch"
+	cols := make([][]interface{}, 4+len(qk)+len(sk))
+	for j := range cols {
+		cols[j] = make([]interface{}, n)
+	}
+	for _, k := range qk {
+		hdr += ",q." + k
+	}
+	for _, k := range sk {
+		hdr += ",s." + k
+	}
+	for i, f := range fs {
+		cols[0][i] = dt[i]
+		cols[1][i] = f.AET
+		cols[2][i] = f.Runoff
+		cols[3][i] = f.Recharge
+		for j, k := range qk {
+			cols[4+j][i] = f.Q[k]
+		}
+		for j, k := range sk {
+			cols[4+len(qk)+j][i] = f.S[k]
+		}
+	}
+	mmio.WriteCSV(fp, hdr, cols...)
+}
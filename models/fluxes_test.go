@@ -0,0 +1,30 @@
+package rainrun
+
+import "testing"
+
+func TestUpdateFluxes(t *testing.T) {
+	ds := synthetic(400, secPerDay)
+	for _, l := range lumpers() {
+		if _, ok := l.new().(Fluxer); !ok {
+			continue
+		}
+		t.Run(l.name, func(t *testing.T) {
+			a, b := l.new(), l.new()
+			for _, m := range []Lumper{a, b} {
+				if err := m.New(ds, l.params()...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i, v := range ds.FRC {
+				ae, q, g := a.Update(v[0], v[1])
+				f := b.(Fluxer).UpdateFluxes(v[0], v[1])
+				if !same(f.AET, ae) || !same(f.Runoff, q) || !same(f.Recharge, g) {
+					t.Fatalf("step %d: fluxes %g %g %g, updated %g %g %g", i+1, f.AET, f.Runoff, f.Recharge, ae, q, g)
+				}
+				if len(f.Q) == 0 || len(f.S) == 0 {
+					t.Fatalf("step %d: missing flux components or storages", i+1)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package rainrun
+
+import "fmt"
+
+// FrontEnd pairs a Lumper with snow and PET modules, forming a Climater.
+// Parameters are ordered [Lumper, Snow, PET]; the Lumper must be a Describer.
+type FrontEnd struct {
+	M    Lumper
+	Snow Snow
+	PET  PET
+	nm   int
+}
+
+// NewFrontEnd returns Lumper m forced through snow module sn and PET module ep
+func NewFrontEnd(m Lumper, sn Snow, ep PET) *FrontEnd {
+	return &FrontEnd{M: m, Snow: sn, PET: ep}
+}
+
+// New builds the Lumper, snow and PET modules from the concatenated parameters p
+func (f *FrontEnd) New(ds *Dataset, p ...float64) error {
+	d, ok := f.M.(Describer)
+	if !ok {
+		return fmt.Errorf("FrontEnd: %T does not describe its parameters", f.M)
+	}
+	f.nm = len(d.Parameters())
+	ns := len(f.Snow.Parameters())
+	if n := f.nm + ns + len(f.PET.Parameters()); len(p) != n {
+		return &ParameterError{"FrontEnd", fmt.Sprintf("expecting %d parameters", n), p}
+	}
+	if err := f.M.New(ds, p[:f.nm]...); err != nil {
+		return err
+	}
+	if err := f.Snow.New(ds, p[f.nm:f.nm+ns]...); err != nil {
+		return err
+	}
+	return f.PET.New(ds, p[f.nm+ns:]...)
+}
+
+// Update state
+func (f *FrontEnd) Update(v []float64, doy int) (y, a, r, g float64) {
+	y, ep := f.Snow.Update(v, doy), f.PET.Update(v, doy)
+	a, r, g = f.M.Update(y, ep)
+	return
+}
+
+// UpdateFluxes updates state, adding snowpack yield (y), PET (ep) and snowpack water content (swe) to the Lumper fluxes
+func (f *FrontEnd) UpdateFluxes(v []float64, doy int) Fluxes {
+	y, ep := f.Snow.Update(v, doy), f.PET.Update(v, doy)
+	var fl Fluxes
+	if fm, ok := f.M.(Fluxer); ok {
+		fl = fm.UpdateFluxes(y, ep)
+	} else {
+		fl.AET, fl.Runoff, fl.Recharge = f.M.Update(y, ep)
+		fl.Q, fl.S = make(map[string]float64), make(map[string]float64)
+	}
+	fl.Q["y"], fl.Q["ep"] = y, ep
+	fl.S["swe"] = f.Snow.SWE()
+	return fl
+}
+
+// Storage returns the Lumper storage plus the snowpack water content
+func (f *FrontEnd) Storage() float64 {
+	return f.M.Storage() + f.Snow.SWE()
+}
+
+// Parameters describes the Lumper, snow and PET parameters
+func (f *FrontEnd) Parameters() []Parameter {
+	var ps []Parameter
+	if d, ok := f.M.(Describer); ok {
+		ps = d.Parameters()
+	}
+	return append(ps, append(f.Snow.Parameters(), f.PET.Parameters()...)...)
+}
+
+// State returns the Lumper state followed by the snowpack state
+func (f *FrontEnd) State() State {
+	return joinState(f.M.State(), f.Snow.State())
+}
+
+// SetState restores a state returned by State
+func (f *FrontEnd) SetState(s State) error {
+	sm, ss, err := splitState(s, f.Snow.State())
+	if err != nil {
+		return err
+	}
+	if err := f.M.SetState(sm); err != nil {
+		return err
+	}
+	return f.Snow.SetState(ss)
+}
+
+// Exchange returns the external exchange of the last update of the Lumper and snow module
+func (f *FrontEnd) Exchange() float64 {
+	return Exchange(f.M) + Exchange(f.Snow)
+}
+
+// Err returns the first numerical error raised by the Lumper
+func (f *FrontEnd) Err() error {
+	return UpdateError(f.M)
+}
+
+// joinState appends snowpack state sn to the state s of the model it forces
+func joinState(s, sn State) State {
+	s.S = append(s.S, sn.S...)
+	s.V = append(s.V, sn.V...)
+	return s
+}
+
+// splitState separates a state built by joinState, sized by the current snowpack state sn
+func splitState(s, sn State) (State, State, error) {
+	i, j := len(s.S)-len(sn.S), len(s.V)-len(sn.V)
+	if i < 0 || j < 0 {
+		return s, sn, fmt.Errorf("SetState error: missing snowpack state")
+	}
+	return State{S: s.S[:i], V: s.V[:j]}, State{S: s.S[i:], V: s.V[j:]}, nil
+}
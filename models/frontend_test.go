@@ -0,0 +1,68 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// climateCloses runs Climater m with parameters p over dataset ds, failing t when
+// rain + snow + X - AET - Q = dS does not close at any step
+func climateCloses(t *testing.T, m Climater, ds *Dataset, p ...float64) {
+	t.Helper()
+	if err := m.New(ds, p...); err != nil {
+		t.Fatalf("%T.New: %v", m, err)
+	}
+	for i, v := range ds.FRC {
+		s0 := m.Storage()
+		_, a, r, _ := m.Update(v, ds.DOY[i])
+		if e := v[2] + v[3] + Exchange(m) - a - r - (m.Storage() - s0); math.Abs(e) > 1e-9 {
+			t.Fatalf("%T: mass balance error at step %d: residual %.3e", m, i+1, e)
+		}
+	}
+	if err := UpdateError(m); err != nil {
+		t.Fatalf("%T: %v", m, err)
+	}
+}
+
+func TestFrontEndClosure(t *testing.T) {
+	ccf := []float64{.002, 1.1, 0., .3, .0045}
+	for _, ts := range []float64{secPerDay, 3600.} {
+		ds := climate(730, ts)
+		gr4j, hbv := mid((&GR4J{}).Parameters()), mid((&HBV{}).Parameters())
+		for _, c := range []struct {
+			name string
+			m    Climater
+			p    []float64
+		}{
+			{"CCFGR4J", &CCFGR4J{}, append(append([]float64{}, gr4j...), ccf...)},
+			{"CCFHBV", &CCFHBV{}, append(append([]float64{}, hbv...), ccf...)},
+			{"MakkinkCCFGR4J", &MakkinkCCFGR4J{}, append(append(append([]float64{}, gr4j...), ccf...), 1.13, -.00027)},
+			{"FrontEnd GR4J", NewFrontEnd(&GR4J{}, &CCF{}, &Makkink{}), append(append(append([]float64{}, gr4j...), ccf...), 1.13, -.00027)},
+			{"FrontEnd HBV", NewFrontEnd(&HBV{}, &DegreeDay{}, &Makkink{}), append(append([]float64{}, hbv...), 3e-3, 0., 1.13, -.00027)},
+		} {
+			t.Run(fmt.Sprintf("%s/%.0fs", c.name, ts), func(t *testing.T) {
+				climateCloses(t, c.m, ds, c.p...)
+			})
+		}
+	}
+}
+
+func TestFrontEndMatchesCCFGR4J(t *testing.T) {
+	ds := climate(365, secPerDay)
+	p := append(mid((&GR4J{}).Parameters()), .002, 1.1, 0., .3, .0045)
+	m, f := &CCFGR4J{}, NewFrontEnd(&GR4J{}, &CCF{}, &Makkink{})
+	if err := m.New(ds, p...); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.New(ds, append(p, 1.13, -.00027)...); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range ds.FRC {
+		_, _, r1, _ := m.Update(v, ds.DOY[i])
+		_, _, r2, _ := f.Update(v, ds.DOY[i])
+		if r1 != r2 || m.Storage() != f.Storage() {
+			t.Fatalf("step %d: CCFGR4J q=%g s=%g, FrontEnd q=%g s=%g", i+1, r1, m.Storage(), r2, f.Storage())
+		}
+	}
+}
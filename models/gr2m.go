@@ -0,0 +1,106 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+)
+
+// GR2M monthly model
+// Mouelhi, S., C. Michel, C. Perrin, V. Andréassian, 2006. Stepwise development of a two-parameter monthly water balance model. Journal of Hydrology 318. pp. 200-214.
+type GR2M struct {
+	prd, rte res
+	x2, g    float64 // g: groundwater exchange of the last update
+}
+
+// New GR2M constructor
+// [x1, x2]
+func (m *GR2M) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("GR2M", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if !ds.IsMonthly() {
+		return fmt.Errorf("GR2M requires monthly timesteps, see Dataset.ToMonthly")
+	}
+	if p[0] <= 0. || p[1] < 0. {
+		return &ParameterError{"GR2M", "x1 must be > 0, x2 >= 0", p}
+	}
+	m.prd.new(p[0], 0.) // x1: production store capacity
+	m.rte.new(.06, 0.)  // routing store reference capacity, fixed at 60 mm
+	m.x2 = p[1]         // x2: groundwater exchange coefficient
+	m.prd.sto, m.rte.sto = p[0]/2., 0.
+	return nil
+}
+
+// Monthly marks GR2M as a monthly model
+func (m *GR2M) Monthly() {}
+
+// Update state
+func (m *GR2M) Update(p, ep float64) (float64, float64, float64) {
+	a, q, _, g := m.update(p, ep)
+	return a, q, g
+}
+
+// UpdateFluxes updates state, returning percolation (perc) and groundwater exchange (fe, <0 for exports)
+func (m *GR2M) UpdateFluxes(p, ep float64) Fluxes {
+	a, q, perc, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: q, Recharge: g,
+		Q: map[string]float64{"perc": perc, "fe": -g},
+		S: map[string]float64{"prd": m.prd.sto, "rte": m.rte.sto},
+	}
+}
+
+func (m *GR2M) update(p, ep float64) (a, q, perc, g float64) {
+	x1, s := m.prd.cap, m.prd.sto
+
+	// production store
+	phi := math.Tanh(p / x1)
+	s1 := (s + x1*phi) / (1. + phi*s/x1)
+	p1 := p + s - s1 // rainfall excess
+	psi := math.Tanh(ep / x1)
+	s2 := s1 * (1. - psi) / (1. + psi*(1.-s1/x1))
+	a = s1 - s2
+	m.prd.sto = s2 / math.Pow(1.+math.Pow(s2/x1, 3.), 1./3.)
+	perc = s2 - m.prd.sto
+
+	// routing store, with groundwater exchange
+	r1 := m.rte.sto + p1 + perc
+	r2 := m.x2 * r1
+	g = r1 - r2 // exchange lost to (>0) or gained from (<0) deep groundwater
+	q = r2 * r2 / (r2 + m.rte.cap)
+	m.rte.sto = r2 - q
+	m.g = g
+	return
+}
+
+// Exchange returns the groundwater exchange of the last update, reported as recharge
+func (m *GR2M) Exchange() float64 {
+	return -m.g
+}
+
+// Storage returns total storage
+func (m *GR2M) Storage() float64 {
+	return m.prd.sto + m.rte.sto
+}
+
+// State returns the current state [prd, rte]
+func (m *GR2M) State() State {
+	return State{S: []float64{m.prd.sto, m.rte.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *GR2M) SetState(s State) error {
+	if err := s.check("GR2M", 2, 0); err != nil {
+		return err
+	}
+	m.prd.sto, m.rte.sto = s.S[0], s.S[1]
+	return nil
+}
+
+// Parameters describes the GR2M model parameters
+func (m *GR2M) Parameters() []Parameter {
+	return []Parameter{
+		{"x1", "m", "production store capacity", 0., inf, .1, 2., true},
+		{"x2", "-", "groundwater exchange coefficient (<1 for exports, >1 for imports)", 0., inf, .2, 1.3, false},
+	}
+}
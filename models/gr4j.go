@@ -1,11 +1,11 @@
 package rainrun
 
 import (
-	"log"
 	"math"
 
 	"github.com/maseology/glbopt"
 	"github.com/maseology/mmaths"
+	"github.com/maseology/rainrun/routing"
 )
 
 // GR4J model
@@ -13,30 +13,57 @@ import (
 type GR4J struct {
 	prd, rte           res
 	uh1, uh2, cv1, cv2 []float64
-	x2, qsplt          float64
+	x2, x3r, fperc     float64
+	x5                 float64 // GR5J/GR6J exchange threshold
+	qsplt              float64
+	xch                float64 // net groundwater exchange of the last update
+	thresh             bool    // threshold groundwater exchange (GR5J/GR6J)
+	name               string
+	err                error
 }
 
 // New GR4J constructor
-func (m *GR4J) New(p ...float64) {
-	if p[3] < 0.5 { //|| p[4] <= 0. || p[4] >= 1.0 {
-		log.Fatalln("GR4J input error")
+func (m *GR4J) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("GR4J", p, len(m.Parameters())); err != nil {
+		return err
 	}
+	return m.new(ds, p, "GR4J", false)
+}
 
-	m.prd.new(p[0], 0.) // prd: x1: maximum capacity of the "production (SMA) store"
-	m.x2 = p[1]         // x2: water exchange coefficient (>0 for water imports, <0 for exports, =0 for no exchange)
-	m.rte.new(p[2], 0.) // rte: x3: reference capacity of "routing store"
-	x4 := p[3]          // x4: unit hydrograph time parameter
+// new builds the production and routing stores and unit hydrographs common to
+// the GR models; thresh selects the threshold groundwater exchange of x5=p[4]
+func (m *GR4J) new(ds *Dataset, p []float64, name string, thresh bool) error {
+	if p[0] <= 0. || p[2] <= 0. {
+		return &ParameterError{name, "x1 and x3 must be > 0", p}
+	}
+	if p[3]*secPerDay/ds.tsec() < 0.5 { //|| p[4] <= 0. || p[4] >= 1.0 {
+		return &ParameterError{name, "x4 less than half a timestep", p}
+	}
+	m.name, m.thresh, m.x5 = name, thresh, 0.
+	if thresh {
+		m.x5 = p[4]
+	}
+	m.err = nil
+	ts := ds.tsec()
+	fts := math.Pow(ts/secPerDay, .25) // the percolation and routing power laws are defined for daily steps
+
+	m.prd.new(p[0], 0.)         // prd: x1: maximum capacity of the "production (SMA) store"
+	m.x2 = p[1] * ts            // x2: water exchange coefficient (>0 for water imports, <0 for exports, =0 for no exchange) [m/s]
+	m.rte.new(p[2], 0.)         // rte: x3: reference capacity of "routing store"
+	x4 := p[3] * secPerDay / ts // x4: unit hydrograph time parameter [d], converted to timesteps
 	// m.qsplt = p[4]      // qsplt: unitHydrographPartition, fixed in paper to = 0.9
+	m.fperc = 4. / 9. * fts
+	m.x3r = p[2] / fts
 
 	m.rte.sto = func() float64 {
-		q0 := FRC[0][2]
+		q0 := ds.Obs(0)
 		smpl := func(u float64) float64 {
 			return mmaths.LinearTransform(0., 10., u)
 		}
 		opt := func(u []float64) float64 {
 			x3i := smpl(u[0])
-			qr := p[1] * math.Pow(x3i/p[2], 7./2.)                        // eq.18 catchment GW exchange; x2: water exchange coefficient (>0 for water imports, <0 for exports, =0 for no exchange)
-			qr += x3i * (1. - math.Pow(1.+math.Pow(x3i/p[2], 4.), -0.25)) // eq.20
+			qr := m.exchange(x3i / p[2])                                   // eq.18 catchment GW exchange
+			qr += x3i * (1. - math.Pow(1.+math.Pow(x3i/m.x3r, 4.), -0.25)) // eq.20
 			return math.Abs(qr-q0) / q0
 		}
 		u, _ := glbopt.Fibonacci(opt)
@@ -44,54 +71,45 @@ func (m *GR4J) New(p ...float64) {
 	}()
 
 	// unit hydrographs build
-	func() { // build UH1
-		n := int(math.Ceil(x4))
-		m.uh1 = make([]float64, n)
-		m.cv1 = make([]float64, n-1)
-		sh1 := make([]float64, n)
-		for t := 1; t < n; t++ {
-			tf := float64(t)
-			if tf < x4 {
-				sh1[t] = math.Pow(tf/x4, 2.5)
-			} else {
-				sh1[t] = 1.
-			}
-		}
-		for t := 0; t < n; t++ {
-			if t < n-1 {
-				m.uh1[t] = sh1[t+1] - sh1[t]
-			} else {
-				m.uh1[t] = 1. - sh1[t]
-			}
-		}
-	}()
-	func() { // build UH2
-		n := int(math.Ceil(2. * x4))
-		m.uh2 = make([]float64, n)
-		m.cv2 = make([]float64, n-1)
-		sh2 := make([]float64, n)
-		for t := 1; t < n; t++ {
-			tf := float64(t)
-			if tf <= x4 {
-				sh2[t] = math.Pow(tf/x4, 2.5) / 2.
-			} else if tf < 2.*x4 {
-				sh2[t] = 1. - math.Pow(2.-tf/x4, 2.5)/2.
-			} else {
-				sh2[t] = 1.
-			}
-		}
-		for t := 0; t < n; t++ {
-			if t < n-1 {
-				m.uh2[t] = sh2[t+1] - sh2[t]
-			} else {
-				m.uh2[t] = 1. - sh2[t]
-			}
-		}
-	}()
+	m.uh1, m.uh2 = routing.SH1(x4), routing.SH2(x4)
+	m.cv1, m.cv2 = make([]float64, len(m.uh1)-1), make([]float64, len(m.uh2)-1)
+	return nil
 }
 
-// Update state for daily inputs
+// Update state
 func (m *GR4J) Update(p, ep float64) (float64, float64, float64) {
+	a, qr, qd, _, g := m.update(p, ep)
+	return a, qd + qr, g // eq.23
+}
+
+// UpdateFluxes updates state, returning routed flow (qr), direct flow (qd) and groundwater exchange (fe)
+func (m *GR4J) UpdateFluxes(p, ep float64) Fluxes {
+	a, qr, qd, fe, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qd + qr, Recharge: g,
+		Q: map[string]float64{"qr": qr, "qd": qd, "fe": fe},
+		S: map[string]float64{"prd": m.prd.sto, "rte": m.rte.sto},
+	}
+}
+
+func (m *GR4J) update(p, ep float64) (a, qr, qd, fe, g float64) {
+	var pr float64
+	a, pr, g = m.production(p, ep)
+	q9 := m.updateUH1(.9 * pr) // eq.9-11
+	q1 := m.updateUH2(.1 * pr) // eq.12-17
+	// q9 := m.updateUH1(m.qsplt * pr)        // eq.9-11
+	// q1 := m.updateUH2((1. - m.qsplt) * pr) // eq.12-17
+
+	fe = m.exchange(m.rte.storageFraction()) // eq.18
+	s0 := m.rte.sto
+	qr = m.routing(q9 + fe)  // eq.19-21
+	qd = math.Max(0., q1+fe) // eq.22
+	m.xch = m.rte.sto - s0 + qr - q9 + qd - q1
+	return
+}
+
+// production updates the production store, returning aet, the effective rainfall to be routed (pr) and percolation (g)
+func (m *GR4J) production(p, ep float64) (a, pr, g float64) {
 	var pn, en, es float64
 	if p >= ep {
 		pn = p - ep // eq.1
@@ -108,30 +126,36 @@ func (m *GR4J) Update(p, ep float64) (float64, float64, float64) {
 	}
 	m.prd.update(ps - es) // eq.5
 	if m.prd.storageFraction() > 1.000001 {
-		println(m.prd.sto, m.prd.cap, ps, es)
-		panic("GR4J error: production store error")
+		m.fail("production store error")
 	}
 
-	g := m.prd.sto * (1. - math.Pow(1.+math.Pow(4.*m.prd.storageFraction()/9., 4.), -0.25)) // eq.6 "Perc": percolation from production zone
-	if m.prd.update(-g) < 0. {                                                              // eq.7 this line must be left here such that prd is updated
-		panic("GR4J error: percolation")
+	g = m.prd.sto * (1. - math.Pow(1.+math.Pow(m.fperc*m.prd.storageFraction(), 4.), -0.25)) // eq.6 "Perc": percolation from production zone
+	if m.prd.update(-g) < 0. {                                                               // eq.7 this line must be left here such that prd is updated
+		m.fail("percolation")
 	}
 
-	pr := g + pn - ps          // eq.8
-	q9 := m.updateUH1(.9 * pr) // eq.9-11
-	q1 := m.updateUH2(.1 * pr) // eq.12-17
-	// q9 := m.updateUH1(m.qsplt * pr)        // eq.9-11
-	// q1 := m.updateUH2((1. - m.qsplt) * pr) // eq.12-17
+	pr = g + pn - ps         // eq.8
+	a = es + math.Min(p, ep) // soil evaporation plus PET satisfied by rainfall (eq.1-2)
+	return
+}
 
-	fe := m.x2 * math.Pow(m.rte.storageFraction(), 7./2.)                              // eq.18 catchment GW exchange; x2: water exchange coefficient (>0 for water imports, <0 for exports, =0 for no exchange)
-	m.rte.update(q9 + fe)                                                              // eq.19
-	qr := m.rte.sto * (1. - math.Pow(1.+math.Pow(m.rte.storageFraction(), 4.), -0.25)) // eq.20
-	if m.rte.update(-qr) < 0. {                                                        // eq.21 this line must be left here such that rte is updated
-		panic("GR4J error: routing")
+// exchange returns the catchment groundwater exchange given the routing store fraction sf;
+// x2: water exchange coefficient (>0 for water imports, <0 for exports, =0 for no exchange)
+func (m *GR4J) exchange(sf float64) float64 {
+	if m.thresh {
+		return m.x2 * (sf - m.x5) // GR5J: Le Moine (2008)
 	}
+	return m.x2 * math.Pow(sf, 7./2.) // eq.18
+}
 
-	qd := math.Max(0., q1+fe) // eq.22
-	return es, qd + qr, g     // eq.23
+// routing adds q to the routing store, returning its outflow
+func (m *GR4J) routing(q float64) float64 {
+	m.rte.update(q)                                                            // eq.19
+	qr := m.rte.sto * (1. - math.Pow(1.+math.Pow(m.rte.sto/m.x3r, 4.), -0.25)) // eq.20
+	if m.rte.update(-qr) < 0. {                                                // eq.21 this line must be left here such that rte is updated
+		m.fail("routing")
+	}
+	return qr
 }
 
 func (m *GR4J) updateUH1(pr float64) float64 {
@@ -147,8 +171,11 @@ func (m *GR4J) updateUH1(pr float64) float64 {
 	return q
 }
 func (m *GR4J) updateUH2(pr float64) float64 {
-	q := m.uh2[0]*pr + m.cv2[0]
 	n := len(m.cv2) - 1
+	if n == -1 {
+		return pr
+	}
+	q := m.uh2[0]*pr + m.cv2[0]
 	for i := 0; i < n; i++ {
 		m.cv2[i] = m.uh2[i+1]*pr + m.cv2[i+1]
 	}
@@ -156,7 +183,66 @@ func (m *GR4J) updateUH2(pr float64) float64 {
 	return q
 }
 
-// Storage returns total storage
+// Storage returns total storage, including runoff in transit through the unit hydrographs
 func (m *GR4J) Storage() float64 {
-	return m.prd.sto + m.rte.sto
+	s := m.prd.sto + m.rte.sto
+	for _, v := range m.cv1 {
+		s += v
+	}
+	for _, v := range m.cv2 {
+		s += v
+	}
+	return s
+}
+
+// State returns the current state: stores [prd, rte]; unit hydrograph convolution vectors [cv1, cv2]
+func (m *GR4J) State() State {
+	return State{
+		S: []float64{m.prd.sto, m.rte.sto},
+		V: [][]float64{copyVec(m.cv1), copyVec(m.cv2)},
+	}
+}
+
+// SetState restores a state returned by State; the model must have been built with the same x4
+func (m *GR4J) SetState(s State) error {
+	if err := s.check(m.name, 2, 2); err != nil {
+		return err
+	}
+	if err := s.checkVector(m.name, 0, len(m.cv1)); err != nil {
+		return err
+	}
+	if err := s.checkVector(m.name, 1, len(m.cv2)); err != nil {
+		return err
+	}
+	m.prd.sto, m.rte.sto = s.S[0], s.S[1]
+	copy(m.cv1, s.V[0])
+	copy(m.cv2, s.V[1])
+	return nil
+}
+
+// Parameters describes the GR4J model parameters
+func (m *GR4J) Parameters() []Parameter {
+	return []Parameter{
+		{"x1", "m", "production storage capacity", 0., inf, 0., 2., false},
+		{"x2", "m/s", "water exchange coefficient (>0 for water imports, <0 for exports, =0 for no exchange)", -inf, inf, -1e-5, 1e-5, false},
+		{"x3", "m", "routing storage/groundwater storage capacity", 0., inf, 0., 25., false},
+		{"x4", "d", "unit hydrograph time base", .5, inf, .5, 10., false},
+	}
+}
+
+// Exchange returns the groundwater exchange applied during the last update,
+// net of the routing store and direct flow being held at zero (eq.18-22)
+func (m *GR4J) Exchange() float64 {
+	return m.xch
+}
+
+// Err returns the first numerical error raised during Update
+func (m *GR4J) Err() error {
+	return m.err
+}
+
+func (m *GR4J) fail(msg string) {
+	if m.err == nil {
+		m.err = &NumericalError{m.name, msg}
+	}
 }
@@ -1,55 +1,69 @@
 package rainrun
 
-import (
-	"github.com/maseology/goHydro/pet"
-	"github.com/maseology/goHydro/snowpack"
-	"github.com/maseology/goHydro/solirrad"
-)
-
 // CCFGR4J model
 // Perrin C., C. Michel, V. Andreassian, 2003. Improvement of a parsimonious model for streamflow simulation. Journal of Hydrology 279. pp. 275-289.
+// with CCF snowmelt model and Makkink PET of fixed coefficients
 type CCFGR4J struct {
 	GR4J
-	SP snowpack.CCF
-	SI *solirrad.SolIrad
+	CCF
+	Makkink
 }
 
 // New CCFGR4J contructor
-// [stocap, gwstocap, x4, unitHydrographPartition, x2]
-// [tindex, ddfc, baseT, tsf]
-func (m *CCFGR4J) New(p ...float64) {
-	const ddf = 0.0045
-	// GR4J
-	m.GR4J.New(p...)
-
-	// Cold-content snow melt funciton
-	tindex, ddfc, baseT, tsf := p[4], p[5], p[6], p[7]
-	m.SP = snowpack.NewCCF(tindex, ddf, ddfc, baseT, tsf)
+// [x1, x2, x3, x4]
+// [tindex, ddfc, baseT, tsf, ddf]
+func (m *CCFGR4J) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("CCFGR4J", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if err := m.GR4J.New(ds, p[:4]...); err != nil {
+		return err
+	}
+	if err := m.CCF.New(ds, p[4:9]...); err != nil {
+		return err
+	}
+	return m.Makkink.New(ds, 1.13, -.00027)
 }
 
 // Update state for daily inputs
 func (m *CCFGR4J) Update(v []float64, doy int) (y, a, r, g float64) {
-	tx, tn, r, s := v[0], v[1], v[2], v[3]
-
-	// calculate yield
-	tm := (tx + tn) / 2.
-	y, _ = m.SP.Update(r, s, tm)
-
-	// calculate ep
-	ep := func() float64 {
-		const (
-			alpha = 1.13
-			beta  = -.00027
-			pres  = 101300.
-			a     = 0.75
-			b     = 0.0025
-			c     = 2.5
-		)
-		tm := (tx + tn) / 2.
-		Kg := m.SI.GlobalFromPotential(tx, tn, a, b, c, doy)
-		return pet.Makkink(Kg, tm, pres, alpha, beta)
-	}()
-
+	y, ep := m.CCF.Update(v, doy), m.Makkink.Update(v, doy)
 	a, r, g = m.GR4J.Update(y, ep)
 	return
 }
+
+// UpdateFluxes updates state, adding snowpack yield (y), PET (ep) and snowpack water content (swe) to the GR4J fluxes
+func (m *CCFGR4J) UpdateFluxes(v []float64, doy int) Fluxes {
+	y, ep := m.CCF.Update(v, doy), m.Makkink.Update(v, doy)
+	f := m.GR4J.UpdateFluxes(y, ep)
+	f.Q["y"], f.Q["ep"] = y, ep
+	f.S["swe"] = m.CCF.SWE()
+	return f
+}
+
+// Storage returns the GR4J storage plus the snowpack water content
+func (m *CCFGR4J) Storage() float64 {
+	return m.GR4J.Storage() + m.CCF.SWE()
+}
+
+// Parameters describes the CCFGR4J model parameters
+func (m *CCFGR4J) Parameters() []Parameter {
+	return append(m.GR4J.Parameters(), CCFParameters()...)
+}
+
+// State returns the current GR4J state, followed by the snowpack state
+func (m *CCFGR4J) State() State {
+	return joinState(m.GR4J.State(), m.CCF.State())
+}
+
+// SetState restores a state returned by State
+func (m *CCFGR4J) SetState(s State) error {
+	sg, ss, err := splitState(s, m.CCF.State())
+	if err != nil {
+		return err
+	}
+	if err := m.GR4J.SetState(sg); err != nil {
+		return err
+	}
+	return m.CCF.SetState(ss)
+}
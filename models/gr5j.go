@@ -0,0 +1,55 @@
+package rainrun
+
+import "math"
+
+// GR5J model
+// Le Moine, N., 2008. Le bassin versant de surface vu par le souterrain : une voie d'amélioration des performances et du réalisme des modèles pluie-débit ? PhD thesis, Université Pierre et Marie Curie, Paris. 324 pp.
+// GR4J with a threshold groundwater exchange (x5) and a single unit hydrograph (UH2)
+type GR5J struct {
+	GR4J
+}
+
+// New GR5J constructor
+// [x1, x2, x3, x4, x5]
+func (m *GR5J) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("GR5J", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	return m.new(ds, p, "GR5J", true)
+}
+
+// Update state
+func (m *GR5J) Update(p, ep float64) (float64, float64, float64) {
+	a, qr, qd, _, g := m.update(p, ep)
+	return a, qd + qr, g
+}
+
+// UpdateFluxes updates state, returning routed flow (qr), direct flow (qd) and groundwater exchange (fe)
+func (m *GR5J) UpdateFluxes(p, ep float64) Fluxes {
+	a, qr, qd, fe, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qd + qr, Recharge: g,
+		Q: map[string]float64{"qr": qr, "qd": qd, "fe": fe},
+		S: map[string]float64{"prd": m.prd.sto, "rte": m.rte.sto},
+	}
+}
+
+func (m *GR5J) update(p, ep float64) (a, qr, qd, fe, g float64) {
+	var pr float64
+	a, pr, g = m.production(p, ep)
+	q := m.updateUH2(pr) // all effective rainfall is routed through UH2, then split 90/10
+
+	fe = m.exchange(m.rte.storageFraction())
+	s0 := m.rte.sto
+	qr = m.routing(.9*q + fe)
+	qd = math.Max(0., .1*q+fe)
+	m.xch = m.rte.sto - s0 + qr - q + qd
+	return
+}
+
+// Parameters describes the GR5J model parameters
+func (m *GR5J) Parameters() []Parameter {
+	return append(m.GR4J.Parameters(),
+		Parameter{"x5", "-", "groundwater exchange threshold, as a fraction of the routing store capacity", -inf, inf, 0., 1., false},
+	)
+}
@@ -0,0 +1,107 @@
+package rainrun
+
+import "math"
+
+// GR6J model
+// Pushpalatha, R., C. Perrin, N. Le Moine, T. Mathevet, V. Andréassian, 2011. A downward structural sensitivity analysis of hydrological models to improve low-flow simulation. Journal of Hydrology 411. pp. 66-76.
+// GR5J threshold groundwater exchange, with GR4J unit hydrographs and an exponential store (x6) taking 40% of UH1 outflow
+type GR6J struct {
+	GR4J
+	exp, x6 float64 // exponential store level (may be negative); x6: exponential store coefficient
+}
+
+// New GR6J constructor
+// [x1, x2, x3, x4, x5, x6]
+func (m *GR6J) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("GR6J", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if p[5] <= 0. {
+		return &ParameterError{"GR6J", "x6 must be > 0", p}
+	}
+	if err := m.new(ds, p, "GR6J", true); err != nil {
+		return err
+	}
+	m.x6 = p[5]
+	m.exp = 0.
+	return nil
+}
+
+// Update state
+func (m *GR6J) Update(p, ep float64) (float64, float64, float64) {
+	a, qr, qd, qe, _, g := m.update(p, ep)
+	return a, qd + qr + qe, g
+}
+
+// UpdateFluxes updates state, returning routed flow (qr), direct flow (qd), exponential store outflow (qe) and groundwater exchange (fe)
+func (m *GR6J) UpdateFluxes(p, ep float64) Fluxes {
+	a, qr, qd, qe, fe, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qd + qr + qe, Recharge: g,
+		Q: map[string]float64{"qr": qr, "qd": qd, "qe": qe, "fe": fe},
+		S: map[string]float64{"prd": m.prd.sto, "rte": m.rte.sto, "exp": m.exp},
+	}
+}
+
+func (m *GR6J) update(p, ep float64) (a, qr, qd, qe, fe, g float64) {
+	var pr float64
+	a, pr, g = m.production(p, ep)
+	q9 := m.updateUH1(.9 * pr)
+	q1 := m.updateUH2(.1 * pr)
+
+	fe = m.exchange(m.rte.storageFraction())
+	s0 := m.rte.sto
+	qr = m.routing(.6*q9 + fe)
+	qe = m.exponential(.4*q9 + fe)
+	qd = math.Max(0., q1+fe)
+	m.xch = m.rte.sto - s0 + qr - .6*q9 + fe + qd - q1 // the exponential store takes fe in full
+	return
+}
+
+// exponential adds q to the exponential store, returning its outflow
+func (m *GR6J) exponential(q float64) float64 {
+	m.exp += q
+	ar := math.Max(-33., math.Min(m.exp/m.x6, 33.))
+	var qe float64
+	switch {
+	case ar > 7.:
+		qe = m.exp + m.x6/math.Exp(ar)
+	case ar < -7.:
+		qe = m.x6 * math.Exp(ar)
+	default:
+		qe = m.x6 * math.Log(math.Exp(ar)+1.)
+	}
+	m.exp -= qe
+	return qe
+}
+
+// Storage returns total storage, including the exponential store
+func (m *GR6J) Storage() float64 {
+	return m.GR4J.Storage() + m.exp
+}
+
+// State returns the current state: stores [prd, rte, exp]; unit hydrograph convolution vectors [cv1, cv2]
+func (m *GR6J) State() State {
+	s := m.GR4J.State()
+	s.S = append(s.S, m.exp)
+	return s
+}
+
+// SetState restores a state returned by State; the model must have been built with the same x4
+func (m *GR6J) SetState(s State) error {
+	if err := s.check("GR6J", 3, 2); err != nil {
+		return err
+	}
+	if err := m.GR4J.SetState(State{S: s.S[:2], V: s.V}); err != nil {
+		return err
+	}
+	m.exp = s.S[2]
+	return nil
+}
+
+// Parameters describes the GR6J model parameters
+func (m *GR6J) Parameters() []Parameter {
+	return append((&GR5J{}).Parameters(),
+		Parameter{"x6", "m", "exponential store coefficient", 0., inf, 1e-4, .05, true},
+	)
+}
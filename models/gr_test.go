@@ -0,0 +1,40 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGRExchange(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		m      GR4J
+		sf, fe float64
+	}{
+		{"GR4J eq.18", GR4J{x2: -.001}, .5, -.001 * math.Pow(.5, 3.5)},
+		{"GR4J full store", GR4J{x2: .002}, 1., .002},
+		{"GR5J below threshold", GR4J{x2: -.001, x5: .4, thresh: true}, .2, .0002},
+		{"GR5J above threshold", GR4J{x2: -.001, x5: .4, thresh: true}, .9, -.0005},
+	} {
+		if fe := c.m.exchange(c.sf); math.Abs(fe-c.fe) > 1e-15 {
+			t.Errorf("%s: got %g, want %g", c.name, fe, c.fe)
+		}
+	}
+}
+
+func TestGR6JExponential(t *testing.T) {
+	for _, c := range []struct {
+		exp, q, qe float64
+	}{
+		{0., 0., .01 * math.Log(2.)},      // softplus at zero
+		{0., .5, .5 + .01*math.Exp(-33.)}, // ar clamped at 33
+		{0., -.5, .01 * math.Exp(-33.)},   // ar clamped at -33
+	} {
+		m := GR6J{exp: c.exp, x6: .01}
+		s0 := m.exp
+		qe := m.exponential(c.q)
+		if math.Abs(qe-c.qe) > 1e-12 || math.Abs(s0+c.q-qe-m.exp) > 1e-15 {
+			t.Errorf("exp=%g q=%g: got qe=%g exp=%g, want qe=%g", c.exp, c.q, qe, m.exp, c.qe)
+		}
+	}
+}
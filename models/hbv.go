@@ -12,26 +12,33 @@ import (
 type HBV struct {
 	tf                                                          transfunc.TF
 	fc, lp, beta, sm, suz, slz, uzl, k0, k1, k2, perc, lakefrac float64
+	err                                                         error
 }
 
 // New HBV constructor
 // [fc, lp, beta, uzl, k0, k1, k2, ksat, maxbas, lakeCoverFrac]
-func (m *HBV) New(p ...float64) {
-	if fracCheck(p[1]) || fracCheck(p[4]) || fracCheck(p[5]) || fracCheck(p[6]) { // || fracCheck(p[9]) {
-		panic("HBV input eror")
+func (m *HBV) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("HBV", p, len(m.Parameters())); err != nil {
+		return err
 	}
-	m.fc = p[0]                         // max basin moisture storage
-	m.lp = p[1]                         // soil moisture parameter
-	m.beta = p[2]                       // soil moisture parameter
-	m.uzl = p[3]                        // upper zone fast flow limit
-	m.k0, m.k1, m.k2 = p[4], p[5], p[6] // fast, slow, and baseflow recession coefficients
-	m.perc = p[7]                       // upper-to-lower zone percolation, assuming percolation rate = Ksat
-	m.lakefrac = 0.                     //p[9]                   // lake fraction
-
-	m.tf = transfunc.NewTF(p[8], 0.5, 0.) // MAXBAS: triangular weighted transfer function
+	if fracCheck(p[1]) || p[4] < 0. || p[5] < 0. || p[6] < 0. { // || fracCheck(p[9]) {
+		return &ParameterError{"HBV", "lp must be [0,1]; k0, k1 and k2 must be >= 0", p}
+	}
+	ts := ds.tsec()
+	m.fc = p[0]                                                                      // max basin moisture storage
+	m.lp = p[1]                                                                      // soil moisture parameter
+	m.beta = p[2]                                                                    // soil moisture parameter
+	m.uzl = p[3]                                                                     // upper zone fast flow limit
+	m.k0, m.k1, m.k2 = recession(p[4], ts), recession(p[5], ts), recession(p[6], ts) // fast, slow, and baseflow recession coefficients [1/s]
+	m.perc = p[7] * ts                                                               // upper-to-lower zone percolation, assuming percolation rate = Ksat [m/s]
+	m.lakefrac = 0.                                                                  //p[9]                   // lake fraction
+
+	m.tf = transfunc.NewTF(p[8]*secPerDay/ts, 0.5, 0.) // MAXBAS: triangular weighted transfer function [d], converted to timesteps
+	m.err = nil
+	return nil
 }
 
-// Update state for daily inputs
+// Update state
 func (m *HBV) Update(pn, ep float64) (float64, float64, float64) {
 	var a float64
 	if m.lakefrac > 0. {
@@ -40,10 +47,26 @@ func (m *HBV) Update(pn, ep float64) (float64, float64, float64) {
 	}
 	m.hBVinfiltration(pn * (1. - m.lakefrac))
 	a += m.hBVet(ep)
-	q, g := m.hBVrunoff()
+	_, _, _, q, g := m.hBVrunoff()
 	return a, q, g
 }
 
+// UpdateFluxes updates state, returning fast runoff (q0), slow runoff (q1) and baseflow (q2) prior to MAXBAS routing
+func (m *HBV) UpdateFluxes(pn, ep float64) Fluxes {
+	var a float64
+	if m.lakefrac > 0. {
+		a = m.hBVlake(pn, ep)
+	}
+	m.hBVinfiltration(pn * (1. - m.lakefrac))
+	a += m.hBVet(ep)
+	q0, q1, q2, q, g := m.hBVrunoff()
+	return Fluxes{
+		AET: a, Runoff: q, Recharge: g,
+		Q: map[string]float64{"q0": q0, "q1": q1, "q2": q2},
+		S: map[string]float64{"sm": m.sm, "suz": m.suz, "slz": m.slz},
+	}
+}
+
 func (m *HBV) hBVlake(pn, ep float64) float64 {
 	m.slz += pn * m.lakefrac // assumes lakes are connected to the lower reservoir
 	epl := ep * m.lakefrac
@@ -56,8 +79,8 @@ func (m *HBV) hBVlake(pn, ep float64) float64 {
 }
 func (m *HBV) hBVinfiltration(p float64) {
 	i := p * math.Pow(m.sm/m.fc, m.beta)
-	if i > p {
-		panic("HBV error, infiltration")
+	if i > p && m.err == nil {
+		m.err = &NumericalError{"HBV", "infiltration"}
 	}
 	m.sm += p - i // soil zone moisture storage
 	if m.sm > m.fc {
@@ -76,33 +99,78 @@ func (m *HBV) hBVet(ep float64) float64 {
 	}
 	return etr
 }
-func (m *HBV) hBVrunoff() (float64, float64) {
+func (m *HBV) hBVrunoff() (q0, q1, q2, q, g float64) {
 	// groundwater accounting
-	q0 := math.Max(m.k0*(m.suz-m.uzl), 0.0) // fast runoff
+	q0 = math.Max(m.k0*(m.suz-m.uzl), 0.0) // fast runoff
 	m.suz -= q0
-	q1 := m.k1 * m.suz // slow runoff
-	m.suz -= q1        // q0 + q1 'total runoff
-	q2 := m.k2 * m.slz // baseflow
-	m.slz -= q2        // lower zone moisture storage
+	q1 = m.k1 * m.suz // slow runoff
+	m.suz -= q1       // q0 + q1 'total runoff
+	q2 = m.k2 * m.slz // baseflow
+	m.slz -= q2       // lower zone moisture storage
 
 	// stream flow response function
 	rgen := q0 + q1 + q2 // generated runoff
 	for i := 1; i <= len(m.tf.QT); i++ {
 		m.tf.SQ[i-1] = m.tf.SQ[i] + m.tf.QT[i-1]*rgen
 	}
-	q := m.tf.SQ[0]
+	q = m.tf.SQ[0]
 
 	// percolate to lower reservoir
-	g := math.Min(m.perc, m.suz)
+	g = math.Min(m.perc, m.suz)
 	m.suz -= g
 	m.slz += g
 
-	return q, g
+	return
 }
 
-// Storage returns total storage
+// Storage returns total storage, including runoff in transit through the MAXBAS transfer function
 func (m *HBV) Storage() float64 {
-	return m.suz + m.slz
+	s := m.sm + m.suz + m.slz
+	for i := 1; i < len(m.tf.SQ); i++ {
+		s += m.tf.SQ[i]
+	}
+	return s
+}
+
+// State returns the current state: stores [sm, suz, slz]; MAXBAS transfer function [SQ]
+func (m *HBV) State() State {
+	return State{
+		S: []float64{m.sm, m.suz, m.slz},
+		V: [][]float64{copyVec(m.tf.SQ)},
+	}
+}
+
+// SetState restores a state returned by State; the model must have been built with the same maxbas
+func (m *HBV) SetState(s State) error {
+	if err := s.check("HBV", 3, 1); err != nil {
+		return err
+	}
+	if err := s.checkVector("HBV", 0, len(m.tf.SQ)); err != nil {
+		return err
+	}
+	m.sm, m.suz, m.slz = s.S[0], s.S[1], s.S[2]
+	copy(m.tf.SQ, s.V[0])
+	return nil
+}
+
+// Parameters describes the HBV model parameters
+func (m *HBV) Parameters() []Parameter {
+	return []Parameter{
+		{"fc", "m", "max basin moisture storage", 0., inf, 0., 10., false},
+		{"lp", "-", "soil moisture parameter", 0., 1., 0., 1., false},
+		{"beta", "-", "soil moisture parameter", 0., inf, 0., 10., false},
+		{"uzl", "m", "upper zone fast flow limit", 0., inf, 0., 100., false},
+		{"k0", "1/s", "fast recession coefficient", 0., inf, 1e-9, 1e-4, true},
+		{"k1", "1/s", "slow recession coefficient", 0., inf, 1e-9, 1e-4, true},
+		{"k2", "1/s", "baseflow recession coefficient", 0., inf, 1e-9, 1e-4, true},
+		{"perc", "m/s", "upper-to-lower zone percolation, assuming percolation rate = Ksat", 0., inf, 1e-12, 1e-5, true},
+		{"maxbas", "d", "MAXBAS: triangular weighted transfer function base", 0., inf, 0., 10., false},
+	}
+}
+
+// Err returns the first numerical error raised during Update
+func (m *HBV) Err() error {
+	return m.err
 }
 
 // // SampleSpace returns a hypercube from which the optimum resides
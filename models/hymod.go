@@ -0,0 +1,114 @@
+package rainrun
+
+import "math"
+
+// HYMOD model
+// Moore, R.J., 1985. The probability-distributed principle and runoff production at point and basin scales. Hydrological Sciences Journal 30(2). pp. 273-297.
+// Boyle, D.P., 2001. Multicriteria calibration of hydrologic models. PhD dissertation, University of Arizona. 212 pp.
+// Pareto-distributed soil moisture store, routed through a Nash cascade of three quick reservoirs and a single slow reservoir
+type HYMOD struct {
+	qck              [3]res
+	slw              res
+	s, cmax, b, alph float64
+}
+
+// New HYMOD constructor
+// [cmax, bexp, alpha, ks, kq]
+func (m *HYMOD) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("HYMOD", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if p[0] <= 0. || p[1] < 0. || fracCheck(p[2]) || p[3] < 0. || p[4] < 0. {
+		return &ParameterError{"HYMOD", "cmax > 0; bexp, ks and kq >= 0; alpha must be [0,1]", p}
+	}
+	ts := ds.tsec()
+	m.cmax = p[0] // maximum storage capacity within the catchment
+	m.b = p[1]    // degree of spatial variability of storage capacity
+	m.alph = p[2] // fraction of excess routed through the quick reservoirs
+	m.slw.new(math.MaxFloat64, recession(p[3], ts))
+	for i := range m.qck {
+		m.qck[i].new(math.MaxFloat64, recession(p[4], ts))
+	}
+	m.s = 0.
+	for i := range m.qck {
+		m.qck[i].sto = 0.
+	}
+	m.slw.sto = 0.
+	return nil
+}
+
+// Update state
+func (m *HYMOD) Update(p, ep float64) (float64, float64, float64) {
+	a, qq, qs, g := m.update(p, ep)
+	return a, qq + qs, g
+}
+
+// UpdateFluxes updates state, returning quick (qq) and slow (qs) flow
+func (m *HYMOD) UpdateFluxes(p, ep float64) Fluxes {
+	a, qq, qs, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qq + qs, Recharge: g,
+		Q: map[string]float64{"qq": qq, "qs": qs},
+		S: map[string]float64{"s": m.s, "q1": m.qck[0].sto, "q2": m.qck[1].sto, "q3": m.qck[2].sto, "slw": m.slw.sto},
+	}
+}
+
+func (m *HYMOD) update(p, ep float64) (a, qq, qs, g float64) {
+	smax := m.cmax / (m.b + 1.)
+	ct := m.cmax * (1. - math.Pow(math.Max(1.-m.s/smax, 0.), 1./(m.b+1.))) // critical capacity, given current storage
+	er1 := math.Max(p-m.cmax+ct, 0.)                                       // excess beyond maximum capacity
+	pn := p - er1
+	s1 := smax * (1. - math.Pow(1.-math.Min((ct+pn)/m.cmax, 1.), m.b+1.))
+	er2 := math.Max(pn-(s1-m.s), 0.) // excess from the probability-distributed stores
+	a = math.Min(s1, s1/smax*ep)
+	m.s = s1 - a
+
+	ov := er1 + er2
+	qq = m.alph * ov
+	for i := range m.qck { // Nash cascade
+		m.qck[i].update(qq)
+		qq = m.qck[i].decayExp()
+	}
+	g = (1. - m.alph) * ov // to slow reservoir
+	m.slw.update(g)
+	qs = m.slw.decayExp()
+	return
+}
+
+// Storage returns total storage
+func (m *HYMOD) Storage() float64 {
+	s := m.s + m.slw.sto
+	for _, r := range m.qck {
+		s += r.sto
+	}
+	return s
+}
+
+// State returns the current state [s, q1, q2, q3, slw]
+func (m *HYMOD) State() State {
+	return State{S: []float64{m.s, m.qck[0].sto, m.qck[1].sto, m.qck[2].sto, m.slw.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *HYMOD) SetState(s State) error {
+	if err := s.check("HYMOD", 5, 0); err != nil {
+		return err
+	}
+	m.s = s.S[0]
+	for i := range m.qck {
+		m.qck[i].sto = s.S[i+1]
+	}
+	m.slw.sto = s.S[4]
+	return nil
+}
+
+// Parameters describes the HYMOD model parameters
+func (m *HYMOD) Parameters() []Parameter {
+	return []Parameter{
+		{"cmax", "m", "maximum storage capacity within the catchment", 0., inf, .001, 1., false},
+		{"bexp", "-", "degree of spatial variability of storage capacity", 0., inf, 0., 2., false},
+		{"alpha", "-", "fraction of excess routed through the quick reservoirs", 0., 1., 0., 1., false},
+		{"ks", "1/s", "slow reservoir recession coefficient", 0., inf, 1e-9, 1e-5, true},
+		{"kq", "1/s", "quick reservoir recession coefficient", 0., inf, 1e-7, 1e-4, true},
+	}
+}
@@ -0,0 +1,15 @@
+package rainrun
+
+import "testing"
+
+func TestHYMOD(t *testing.T) {
+	// [cmax, bexp, alpha, ks, kq]
+	uniform, pareto := []float64{.1, 0., 1., 0., 1.}, []float64{.1, 1., 1., 0., 1.}
+	// stores [s, ..]
+	checkSteps(t, func() Lumper { return &HYMOD{} }, &Dataset{Timestep: secPerDay}, []step{
+		{"uniform capacity spills", uniform, State{S: []float64{0.}}, .15, 0., 0., .05, 0., []float64{.1}},
+		{"uniform capacity absorbs", uniform, State{S: []float64{.02}}, .05, 0., 0., 0., 0., []float64{.07}},
+		{"evaporation scales with storage", uniform, State{S: []float64{.05}}, 0., .004, .002, 0., 0., []float64{.048}},
+		{"pareto store on empty", pareto, State{S: []float64{0.}}, .02, 0., 0., .02 * .02 / (2. * .1), 0., []float64{.02 - .02*.02/(2.*.1)}},
+	})
+}
@@ -0,0 +1,103 @@
+package rainrun
+
+import "math"
+
+// IHACRES model: catchment moisture deficit (CMD) non-linear loss module with a two-store (quick, slow) parallel linear unit hydrograph
+// Jakeman, A.J., G.M. Hornberger, 1993. How much complexity is warranted in a rainfall-runoff model? Water Resources Research 29(8). pp. 2637-2649.
+// Croke, B.F.W., A.J. Jakeman, 2004. A catchment moisture deficit module for the IHACRES rainfall-runoff model. Environmental Modelling & Software 19. pp. 1-5.
+type IHACRES struct {
+	qck, slw   res
+	cmd        float64 // catchment moisture deficit
+	f, e, d, v float64
+}
+
+// New IHACRES constructor
+// [f, e, d, tq, ts, vs]
+func (m *IHACRES) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("IHACRES", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if p[0] <= 0. || p[1] < 0. || p[2] <= 0. || p[3] <= 0. || p[4] <= 0. || fracCheck(p[5]) {
+		return &ParameterError{"IHACRES", "f, d, tq and ts must be > 0, e >= 0, vs must be [0,1]", p}
+	}
+	ts := ds.tsec()
+	m.f = p[0] // stress threshold, as a fraction of d
+	m.e = p[1] // PET to ET conversion factor
+	m.d = p[2] // flow threshold
+	m.qck.new(math.MaxFloat64, recession(1./(p[3]*secPerDay), ts))
+	m.slw.new(math.MaxFloat64, recession(1./(p[4]*secPerDay), ts))
+	m.v = p[5]                                // fraction of effective rainfall routed through the slow store
+	m.cmd, m.qck.sto, m.slw.sto = m.d, 0., 0. // initial deficit set to the flow threshold
+	return nil
+}
+
+// Update state
+func (m *IHACRES) Update(p, ep float64) (float64, float64, float64) {
+	a, qq, qs, g := m.update(p, ep)
+	return a, qq + qs, g
+}
+
+// UpdateFluxes updates state, returning effective rainfall (u), quick (qq) and slow (qs) flow
+func (m *IHACRES) UpdateFluxes(p, ep float64) Fluxes {
+	cmd0 := m.cmd
+	a, qq, qs, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qq + qs, Recharge: g,
+		Q: map[string]float64{"u": p - (cmd0 - m.cmd + a), "qq": qq, "qs": qs},
+		S: map[string]float64{"cmd": m.cmd, "qck": m.qck.sto, "slw": m.slw.sto},
+	}
+}
+
+func (m *IHACRES) update(p, ep float64) (a, qq, qs, g float64) {
+	// CMD loss module (linear form, Croke and Jakeman, 2004)
+	var mf float64 // deficit following rainfall
+	switch {
+	case m.cmd < m.d:
+		mf = m.cmd * math.Exp(-p/m.d)
+	case m.cmd < m.d+p:
+		mf = m.d * math.Exp((m.cmd-m.d-p)/m.d)
+	default:
+		mf = m.cmd - p
+	}
+	u := p - (m.cmd - mf)                                       // effective rainfall
+	a = m.e * ep * math.Min(1., math.Exp(2.*(1.-mf/(m.f*m.d)))) // evapotranspiration, reduced above the stress threshold
+	m.cmd = mf + a
+
+	// unit hydrograph: parallel linear stores
+	g = m.v * u
+	m.qck.update(u - g)
+	m.slw.update(g)
+	qq, qs = m.qck.decayExp(), m.slw.decayExp()
+	return
+}
+
+// Storage returns total storage, less the catchment moisture deficit
+func (m *IHACRES) Storage() float64 {
+	return m.qck.sto + m.slw.sto - m.cmd
+}
+
+// State returns the current state [cmd, qck, slw]
+func (m *IHACRES) State() State {
+	return State{S: []float64{m.cmd, m.qck.sto, m.slw.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *IHACRES) SetState(s State) error {
+	if err := s.check("IHACRES", 3, 0); err != nil {
+		return err
+	}
+	m.cmd, m.qck.sto, m.slw.sto = s.S[0], s.S[1], s.S[2]
+	return nil
+}
+
+// Parameters describes the IHACRES model parameters
+func (m *IHACRES) Parameters() []Parameter {
+	return []Parameter{
+		{"f", "-", "CMD stress threshold, as a fraction of d", 0., inf, .01, 3., false},
+		{"e", "-", "PET to ET conversion factor", 0., inf, .01, 1.5, false},
+		{"d", "m", "CMD flow threshold", 0., inf, .05, .55, false},
+		{"tq", "d", "quick flow time constant", 0., inf, .5, 10., true},
+		{"ts", "d", "slow flow time constant", 0., inf, 10., 500., true},
+		{"vs", "-", "fraction of effective rainfall routed through the slow store", 0., 1., 0., 1., false},
+	}
+}
@@ -0,0 +1,19 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIHACRES(t *testing.T) {
+	p := []float64{1., 1., .1, 1., 100., 0.} // [f, e, d, tq, ts, vs]
+	k := 1. - math.Exp(-1.)                  // daily quick store recession for tq = 1 d
+	// stores [cmd, ..]
+	checkSteps(t, func() Lumper { return &IHACRES{} }, &Dataset{Timestep: secPerDay}, []step{
+		{"dry at the flow threshold", p, State{S: []float64{.1}}, 0., .004, .004, 0., 0., []float64{.104}},
+		{"dry beyond the stress threshold", p, State{S: []float64{.2}}, 0., .004, .004 * math.Exp(-2.), 0., 0., []float64{.2 + .004*math.Exp(-2.)}},
+		{"wet below the flow threshold", p, State{S: []float64{.05}}, .02, 0., 0., k * (.02 - .05 + .05*math.Exp(-.2)), 0., []float64{.05 * math.Exp(-.2)}},
+		{"wet across the flow threshold", p, State{S: []float64{.11}}, .02, 0., 0., k * (.02 - .11 + .1*math.Exp(-.1)), 0., []float64{.1 * math.Exp(-.1)}},
+		{"wet above the flow threshold", p, State{S: []float64{.2}}, .02, 0., 0., 0., 0., []float64{.18}},
+	})
+}
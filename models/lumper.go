@@ -1,8 +1,38 @@
 package rainrun
 
-// Lumper : interface to lumped rainfall-runoff models
+import "fmt"
+
+// Model : interface common to all rainfall-runoff models
+type Model interface {
+	New(ds *Dataset, p ...float64) error
+	Storage() float64
+	State() State
+	SetState(s State) error
+}
+
+// Lumper : interface to lumped rainfall-runoff models, forced by atmospheric yield and demand
 type Lumper interface {
-	New(p ...float64)
+	Model
 	Update(p, ep float64) (float64, float64, float64)
-	Storage() float64
+}
+
+// Climater : interface to lumped rainfall-runoff models forced by raw meteorology
+// v: [tmax, tmin, rain, snow]; returns atmospheric yield (rainfall + snowmelt), aet, runoff and recharge
+type Climater interface {
+	Model
+	Update(v []float64, doy int) (y, a, r, g float64)
+}
+
+// Step updates model m, a Lumper or a Climater, with the forcings of timestep i,
+// returning atmospheric yield, aet, runoff and recharge
+func Step(ds *Dataset, m Model, i int) (y, a, r, g float64) {
+	v := ds.FRC[i]
+	switch mm := m.(type) {
+	case Lumper:
+		a, r, g = mm.Update(v[0], v[1])
+		return v[0], a, r, g
+	case Climater:
+		return mm.Update(v, ds.DOY[i])
+	}
+	panic(fmt.Sprintf("rainrun: %T is neither a Lumper nor a Climater", m))
 }
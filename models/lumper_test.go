@@ -0,0 +1,35 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStep(t *testing.T) {
+	ds := climate(10, secPerDay)
+	m := &GR4J{}
+	if err := m.New(ds, mid(m.Parameters())...); err != nil {
+		t.Fatal(err)
+	}
+	ds.FRC[0] = []float64{.01, .002, .001}
+	if y, a, _, _ := Step(ds, m, 0); y != .01 || a != .002 {
+		t.Errorf("Lumper: yield %g, aet %g; want the forcing precipitation .01 and aet .002", y, a)
+	}
+
+	ds = climate(10, secPerDay)
+	c := NewFrontEnd(&GR4J{}, &DegreeDay{}, &Makkink{})
+	if err := c.New(ds, append(mid((&GR4J{}).Parameters()), .003, 0., 1.13, -.00027)...); err != nil {
+		t.Fatal(err)
+	}
+	ds.FRC[0] = []float64{10., 0., .01, .02, .001}
+	if y, _, _, _ := Step(ds, c, 0); math.Abs(y-.025) > 1e-15 { // rain plus a day's melt of 3 mm/°C/d × 5°C
+		t.Errorf("Climater: yield %g, want 0.025", y)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Step did not panic on a Model that is neither a Lumper nor a Climater")
+		}
+	}()
+	Step(ds, struct{ Model }{m}, 0)
+}
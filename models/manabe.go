@@ -9,13 +9,14 @@ type manabe struct {
 }
 
 // new constructor
-func (m *manabe) new(capacity, fexposed, minSto float64) {
+func (m *manabe) new(capacity, fexposed, minSto float64) error {
 	if capacity < 0. || minSto < 0. || minSto > capacity || fexposed < 0.0 {
-		panic("Manabe parameter error")
+		return &ParameterError{"Manabe", "capacity, fexposed and minSto must be >= 0, minSto <= capacity", []float64{capacity, fexposed, minSto}}
 	}
 	m.cap = capacity
 	m.minsto = minSto
 	m.expo = fexposed
+	return nil
 }
 
 // UpdateExposure : change area of reservoir exposed to evaporative forcings
@@ -107,24 +108,46 @@ func (m *manabe) lossExponential(ep, perc, ts float64) (float64, float64) {
 // ManabeGW manabe reserveroir with an added exponential decay reservoir
 type ManabeGW struct {
 	r              manabe
-	gwsto, perc, k float64
+	gwsto, perc, k float64 // k: groundwater recession coefficient, converted to timestep
 }
 
 // New ManabeGW constructor
 // [capacity, fexposed, minSto, perc, kbf]
-func (m *ManabeGW) New(p ...float64) {
-	m.r.new(p[0], p[1], p[2])
-	m.perc = p[3]
-	m.k = p[4]
+func (m *ManabeGW) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("ManabeGW", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if err := m.r.new(p[0], p[1], p[2]); err != nil {
+		return err
+	}
+	ts := ds.tsec()
+	m.perc = p[3] * ts        // [m/s]
+	m.k = recession(p[4], ts) // [1/s]
+	return nil
 }
 
-// Update state for daily inputs
+// Update state
 func (m *ManabeGW) Update(p, ep float64) (float64, float64, float64) {
-	a, q1, g := m.r.update(p, ep, m.perc)
+	a, q1, q2, g := m.update(p, ep)
+	return a, q1 + q2, g
+}
+
+// UpdateFluxes updates state, returning bucket overflow (q1) and groundwater discharge (q2)
+func (m *ManabeGW) UpdateFluxes(p, ep float64) Fluxes {
+	a, q1, q2, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: q1 + q2, Recharge: g,
+		Q: map[string]float64{"q1": q1, "q2": q2},
+		S: map[string]float64{"sto": m.r.sto, "gwsto": m.gwsto},
+	}
+}
+
+func (m *ManabeGW) update(p, ep float64) (a, q1, q2, g float64) {
+	a, q1, g = m.r.update(p, ep, m.perc)
 	m.gwsto += g
-	q2 := m.gwsto * (1. - m.k)
+	q2 = m.gwsto * m.k
 	m.gwsto -= q2
-	return a, q1 + q2, g
+	return
 }
 
 // Storage returns manabe storage
@@ -132,6 +155,32 @@ func (m *ManabeGW) Storage() float64 {
 	return m.r.Storage() + m.gwsto
 }
 
+// State returns the current state [sto, gwsto]
+func (m *ManabeGW) State() State {
+	return State{S: []float64{m.r.sto, m.gwsto}}
+}
+
+// SetState restores a state returned by State
+func (m *ManabeGW) SetState(s State) error {
+	if err := s.check("ManabeGW", 2, 0); err != nil {
+		return err
+	}
+	m.r.sto = s.S[0]
+	m.gwsto = s.S[1]
+	return nil
+}
+
+// Parameters describes the ManabeGW model parameters
+func (m *ManabeGW) Parameters() []Parameter {
+	return []Parameter{
+		{"capacity", "m", "reservoir capacity; sampled jointly with minSto", 0., inf, 0., 1., false},
+		{"fexposed", "-", "area of reservoir exposed to evaporative forcings", 0., 1., 0., 1., false},
+		{"minSto", "m", "minimum storage; sampled jointly with capacity", 0., inf, 0., 1., false},
+		{"perc", "m/s", "percolation rate", 0., inf, 1e-12, 1e-5, true},
+		{"kbf", "1/s", "groundwater recession coefficient", 0., inf, 1e-9, 1e-4, true},
+	}
+}
+
 // // SampleSpace returns a hypercube from which the optimum resides
 // func (m *ManabeGW) SampleSpace(u []float64) []float64 {
 // 	const esdx = 1. // effective maximum soildepth
@@ -2,73 +2,149 @@ package rainrun
 
 import (
 	"encoding/gob"
+	"fmt"
+	"io"
 	"log"
+	"math"
 	"os"
 	"sort"
+	"sync"
 	"time"
 
+	"github.com/maseology/UTM"
 	"github.com/maseology/goHydro/met"
+	"github.com/maseology/goHydro/solirrad"
 	"github.com/maseology/mmio"
 )
 
-// HDR holds header info
-var HDR *met.Header
-
-// FRC holds forcing data
-var FRC [][]float64
-
-// Ndt number of timesteps
-var Ndt int
-
-// DT holds dates
-var DT []time.Time
-
-// DOY hold day of year
-var DOY []int
-
-// Timestep timestep in seconds
-var Timestep float64
-
-// Loc contains location info (coordinates, catchment properties, etc.)
-var Loc []float64
+// Dataset holds the forcings, dates, timestep and location of a single catchment
+type Dataset struct {
+	HDR      *met.Header // header info
+	FRC      [][]float64 // forcing data
+	DT       []time.Time // dates
+	DOY      []int       // day of year
+	Ndt      int         // number of timesteps
+	Timestep float64     // timestep in seconds
+	Loc      []float64   // location info [id, x, y, z, gradient, aspect, area], optionally followed by a topographic index histogram (see SetTopoIndex)
+	UTMZone  int         // UTM zone of the location coordinates (northern hemisphere)
+
+	sionce sync.Once
+	si     *solirrad.SolIrad
+	sierr  error
+}
 
-// LoadMET collect the climate data, set to a global variable
-func LoadMET(fp string, print bool) {
+// LoadMET collect the climate data, returns a new Dataset
+func LoadMET(fp string, print bool) *Dataset {
+	ds := Dataset{}
 	switch mmio.GetExtension(fp) {
 	case ".met":
-		loadMet(fp, print)
+		ds.loadMet(fp, print)
 	case ".gob":
-		loadGob(fp)
+		ds.loadGob(fp)
 	default:
 		log.Fatalf("unknown input data file %s", fp)
 	}
 
-	Ndt = len(DT)
-	DOY = make([]int, Ndt)
-	for i, t := range DT {
-		DOY[i] = t.YearDay()
+	ds.Ndt = len(ds.DT)
+	if ds.Timestep <= 0. && ds.Ndt > 1 {
+		ds.Timestep = ds.DT[1].Sub(ds.DT[0]).Seconds() // .gob forcings carry no timestep
+	}
+	ds.DOY = make([]int, ds.Ndt)
+	for i, t := range ds.DT {
+		ds.DOY[i] = t.YearDay()
+	}
+	return &ds
+}
+
+// Obs returns the observed discharge of timestep i, the last forcing column
+func (ds *Dataset) Obs(i int) float64 {
+	v := ds.FRC[i]
+	return v[len(v)-1]
+}
+
+// Latitude returns the latitude [°] of the dataset location, from its coordinates in UTMZone
+func (ds *Dataset) Latitude() (float64, error) {
+	if len(ds.Loc) < 3 {
+		return 0., fmt.Errorf("dataset location does not carry coordinates")
+	}
+	if ds.UTMZone < 1 || ds.UTMZone > 60 {
+		return 0., fmt.Errorf("dataset location has an unknown UTM zone (%d)", ds.UTMZone)
+	}
+	lat, _, err := UTM.ToLatLon(ds.Loc[1], ds.Loc[2], ds.UTMZone, "", true)
+	return lat, err
+}
+
+// Elevation returns the elevation [m] of the dataset location, zero when not given
+func (ds *Dataset) Elevation() float64 {
+	if len(ds.Loc) < 4 {
+		return 0.
+	}
+	return ds.Loc[3]
+}
+
+// SetTopoIndex stores a topographic index histogram of bin values ti and catchment area fractions af
+// in the dataset location attributes, as [.., n, ti[0..n), af[0..n)] following the 7 location values
+func (ds *Dataset) SetTopoIndex(ti, af []float64) {
+	loc := make([]float64, 7, 8+2*len(ti))
+	copy(loc, ds.Loc)
+	loc = append(loc, float64(len(ti)))
+	loc = append(loc, ti...)
+	ds.Loc = append(loc, af...)
+}
+
+// TopoIndex returns the topographic index histogram held in the dataset location attributes
+func (ds *Dataset) TopoIndex() (ti, af []float64, err error) {
+	if len(ds.Loc) < 8 {
+		return nil, nil, fmt.Errorf("dataset location does not carry a topographic index histogram")
+	}
+	n := int(ds.Loc[7])
+	if n <= 0 || len(ds.Loc) != 8+2*n {
+		return nil, nil, fmt.Errorf("invalid topographic index histogram of %d bins", n)
 	}
+	return ds.Loc[8 : 8+n], ds.Loc[8+n:], nil
 }
 
-func loadGob(fp string) {
+// SolIrad returns the solar irradiation of the dataset location, computed once
+// from its coordinates, gradient and aspect
+func (ds *Dataset) SolIrad() (*solirrad.SolIrad, error) {
+	ds.sionce.Do(func() {
+		if len(ds.Loc) < 6 {
+			ds.sierr = fmt.Errorf("dataset location does not carry coordinates, gradient and aspect")
+			return
+		}
+		lat, err := ds.Latitude()
+		if err != nil {
+			ds.sierr = err
+			return
+		}
+		si := solirrad.New(lat, math.Tan(ds.Loc[4]), ds.Loc[5])
+		ds.si = &si
+	})
+	return ds.si, ds.sierr
+}
+
+func (ds *Dataset) loadGob(fp string) {
 	f, err := os.Open(fp)
 	defer f.Close()
 	if err != nil {
 		log.Fatalf("met.go loadGob error: %v", err)
 	}
 	enc := gob.NewDecoder(f)
-	err = enc.Decode(&FRC)
+	err = enc.Decode(&ds.FRC)
 	if err != nil {
 		log.Fatalf("met.go loadGob error: %v", err)
 	}
-	err = enc.Decode(&DT)
+	err = enc.Decode(&ds.DT)
 	if err != nil {
 		log.Fatalf("met.go loadGob error: %v", err)
 	}
+	if err = enc.Decode(&ds.Loc); err != nil && err != io.EOF { // location attributes are optional
+		log.Fatalf("met.go loadGob error: %v", err)
+	}
 }
 
-func loadMet(fp string, print bool) {
-	Ndt, FRC, HDR = func() (int, [][]float64, *met.Header) {
+func (ds *Dataset) loadMet(fp string, print bool) {
+	ds.Ndt, ds.FRC, ds.HDR = func() (int, [][]float64, *met.Header) {
 		h, c, err := met.ReadMET(fp, print)
 		if err != nil {
 			log.Fatalln(err)
@@ -77,17 +153,17 @@ func loadMet(fp string, print bool) {
 			log.Fatalln("error: currently on simgle-location .met files supported")
 		}
 
-		Timestep = h.IntervalSec()
-		DT = make([]time.Time, 0, len(c.T))
+		ds.Timestep = h.IntervalSec()
+		ds.DT = make([]time.Time, 0, len(c.T))
 		for _, t := range c.T {
-			DT = append(DT, t)
+			ds.DT = append(ds.DT, t)
 		}
-		sort.Slice(DT, func(i, j int) bool { return DT[i].Before(DT[j]) })
+		sort.Slice(ds.DT, func(i, j int) bool { return ds.DT[i].Before(ds.DT[j]) })
 
-		afrc := make([][]float64, 0, len(DT))
+		afrc := make([][]float64, 0, len(ds.DT))
 		switch h.WBCD {
 		case 33554486:
-			for i := range DT {
+			for i := range ds.DT {
 				afrc = append(afrc, []float64{c.D[i][0][0], c.D[i][0][1], c.D[i][0][2], c.D[i][0][3], c.D[i][0][4]})
 			}
 		case 33555968:
@@ -116,13 +192,13 @@ func loadMet(fp string, print bool) {
 
 		switch h.LocationCode() {
 		case 1:
-			Loc = []float64{h.Locations[0][0].(float64)}
+			ds.Loc = []float64{h.Locations[0][0].(float64)}
 		case 16:
 			for k, v := range h.Locations {
-				Loc = make([]float64, 7)
-				Loc[0] = float64(k) // cell id
+				ds.Loc = make([]float64, 7)
+				ds.Loc[0] = float64(k) // cell id
 				for i := 1; i < 7; i++ {
-					Loc[i] = v[i-1].(float64) // x,y,z,gradient,aspect,area
+					ds.Loc[i] = v[i-1].(float64) // x,y,z,gradient,aspect,area
 				}
 			}
 		default:
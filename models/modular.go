@@ -0,0 +1,290 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+)
+
+// Modular model-structure framework, after FUSE
+// Clark, M.P., A.G. Slater, D.E. Rupp, R.A. Woods, J.A. Vrugt, H.V. Gupta, T. Wagener, L.E. Hay, 2008. Framework for Understanding Structural Errors (FUSE): A modular framework to diagnose differences between hydrological models. Water Resources Research 44. W00B02.
+
+// flux sources and sinks other than stores
+const (
+	SourceRain = "rain"   // atmospheric yield
+	SinkET     = "et"     // evapotranspiration
+	SinkOutlet = "outlet" // runoff
+)
+
+// Store declares a store of a model structure
+type Store struct {
+	Name   string
+	Lo, Hi float64 // capacity sampling range [m]; unbounded when Hi <= 0
+	GW     bool    // groundwater store: fluxes into it are reported as recharge
+}
+
+// Flux declares a flux of a model structure, moving water from From to To
+// (stores, SourceRain, SinkET or SinkOutlet) according to flux equation Eq,
+// controlled by the state of store On (defaults to From)
+type Flux struct {
+	Name, From, To, On string
+	Eq                 FluxEq
+}
+
+// Structure declares a model structure: a graph of stores connected by fluxes.
+// Fluxes drawing on rainfall are computed first, the remaining rainfall is added
+// to store Rain, then the remaining fluxes are computed in order, each limited to
+// the water available at its source.
+type Structure struct {
+	Stores []Store
+	Fluxes []Flux
+	Rain   string // store receiving rainfall not diverted by fluxes from SourceRain
+}
+
+// flux source and sink indices
+const (
+	iRain   = -1
+	iET     = -2
+	iOutlet = -3
+)
+
+// Modular is a Lumper built from a Structure
+type Modular struct {
+	st           *Structure
+	sto, cap     []float64
+	from, to, on []int
+	p            [][]float64
+	q            []float64 // fluxes of the last update
+	irain        int
+	ts           float64
+}
+
+// NewModular returns a Lumper of model structure st
+func NewModular(st *Structure) *Modular {
+	return &Modular{st: st}
+}
+
+// New builds the model from parameters p, ordered as described by Parameters
+func (m *Modular) New(ds *Dataset, p ...float64) error {
+	if err := m.resolve(); err != nil {
+		return err
+	}
+	if n := len(m.Parameters()); len(p) != n {
+		return &ParameterError{"Modular", fmt.Sprintf("expecting %d parameters", n), p}
+	}
+	m.ts = ds.tsec()
+	m.sto, m.cap = make([]float64, len(m.st.Stores)), make([]float64, len(m.st.Stores))
+	k := 0
+	for i, s := range m.st.Stores {
+		m.cap[i] = math.Inf(1)
+		if s.Hi > 0. {
+			if p[k] <= 0. {
+				return &ParameterError{"Modular", s.Name + " capacity must be > 0", p}
+			}
+			m.cap[i] = p[k]
+			k++
+		}
+	}
+	m.p = make([][]float64, len(m.st.Fluxes))
+	for i, f := range m.st.Fluxes {
+		ps := f.Eq.Parameters()
+		for j, par := range ps {
+			if v := p[k+j]; v < par.Min || v > par.Max {
+				return &ParameterError{"Modular", fmt.Sprintf("%s.%s out of bounds [%g,%g]", f.Name, par.Name, par.Min, par.Max), p}
+			}
+		}
+		m.p[i] = p[k : k+len(ps)]
+		k += len(ps)
+	}
+	m.q = make([]float64, len(m.st.Fluxes))
+	return nil
+}
+
+// resolve indexes the stores, sources and sinks of the structure's fluxes
+func (m *Modular) resolve() error {
+	if m.st == nil {
+		return fmt.Errorf("Modular: no model structure")
+	}
+	idx := make(map[string]int, len(m.st.Stores))
+	for i, s := range m.st.Stores {
+		if _, ok := idx[s.Name]; ok || s.Name == SourceRain || s.Name == SinkET || s.Name == SinkOutlet {
+			return fmt.Errorf("Modular: invalid or duplicate store name %q", s.Name)
+		}
+		idx[s.Name] = i
+	}
+	lookup := func(nam string, src bool) (int, error) {
+		switch {
+		case src && nam == SourceRain:
+			return iRain, nil
+		case !src && nam == SinkET:
+			return iET, nil
+		case !src && nam == SinkOutlet:
+			return iOutlet, nil
+		}
+		if i, ok := idx[nam]; ok {
+			return i, nil
+		}
+		return 0, fmt.Errorf("Modular: unknown store %q", nam)
+	}
+	var err error
+	if m.irain, err = lookup(m.st.Rain, false); err != nil || m.irain < 0 {
+		return fmt.Errorf("Modular: rainfall must be received by a store, got %q", m.st.Rain)
+	}
+	n := len(m.st.Fluxes)
+	m.from, m.to, m.on = make([]int, n), make([]int, n), make([]int, n)
+	for i, f := range m.st.Fluxes {
+		if f.Eq == nil {
+			return fmt.Errorf("Modular: flux %q has no equation", f.Name)
+		}
+		if m.from[i], err = lookup(f.From, true); err != nil {
+			return err
+		}
+		if m.to[i], err = lookup(f.To, false); err != nil {
+			return err
+		}
+		m.on[i] = m.from[i]
+		if f.On != "" {
+			if m.on[i], err = lookup(f.On, false); err != nil || m.on[i] < 0 {
+				return fmt.Errorf("Modular: flux %q must be controlled by a store, got %q", f.Name, f.On)
+			}
+		}
+		if _, ok := f.Eq.(Bounded); ok && (m.on[i] < 0 || m.st.Stores[m.on[i]].Hi <= 0.) {
+			return fmt.Errorf("Modular: flux %q depends on the capacity of its controlling store, which must be bounded", f.Name)
+		}
+	}
+	return nil
+}
+
+// Update state
+func (m *Modular) Update(p, ep float64) (float64, float64, float64) {
+	return m.update(p, ep)
+}
+
+// UpdateFluxes updates state, returning every declared flux and store by name
+func (m *Modular) UpdateFluxes(p, ep float64) Fluxes {
+	a, r, g := m.update(p, ep)
+	f := Fluxes{
+		AET: a, Runoff: r, Recharge: g,
+		Q: make(map[string]float64, len(m.q)),
+		S: make(map[string]float64, len(m.sto)),
+	}
+	for i, fl := range m.st.Fluxes {
+		f.Q[fl.Name] = m.q[i]
+	}
+	for i, s := range m.st.Stores {
+		f.S[s.Name] = m.sto[i]
+	}
+	return f
+}
+
+func (m *Modular) update(p, ep float64) (a, r, g float64) {
+	pool := p // rainfall yet to be distributed
+	flux := func(i int) {
+		in := FluxInput{Supply: pool, Ep: ep - a, Ts: m.ts}
+		if m.from[i] >= 0 {
+			in.Supply = m.sto[m.from[i]]
+		}
+		if m.on[i] >= 0 {
+			in.S, in.Cap = m.sto[m.on[i]], m.cap[m.on[i]]
+		}
+		v := math.Max(math.Min(m.st.Fluxes[i].Eq.Flux(in, m.p[i]), in.Supply), 0.)
+		if m.to[i] == iET {
+			v = math.Min(v, in.Ep)
+		}
+		m.q[i] = v
+		if m.from[i] == iRain {
+			pool -= v
+		} else {
+			m.sto[m.from[i]] -= v
+		}
+		switch t := m.to[i]; t {
+		case iET:
+			a += v
+		case iOutlet:
+			r += v
+		default:
+			m.sto[t] += v
+			if m.st.Stores[t].GW {
+				g += v
+			}
+		}
+	}
+	for i := range m.st.Fluxes {
+		if m.from[i] == iRain {
+			flux(i)
+		}
+	}
+	m.sto[m.irain] += pool
+	for i := range m.st.Fluxes {
+		if m.from[i] != iRain {
+			flux(i)
+		}
+	}
+	return
+}
+
+// Storage returns total storage
+func (m *Modular) Storage() float64 {
+	s := 0.
+	for _, v := range m.sto {
+		s += v
+	}
+	return s
+}
+
+// State returns the current state: storage of every store, in declared order
+func (m *Modular) State() State {
+	return State{S: copyVec(m.sto)}
+}
+
+// SetState restores a state returned by State
+func (m *Modular) SetState(s State) error {
+	if err := s.check("Modular", len(m.sto), 0); err != nil {
+		return err
+	}
+	copy(m.sto, s.S)
+	return nil
+}
+
+// Parameters describes the model parameters, generated from the structure:
+// store capacities in declared order, followed by the parameters of every flux equation, named flux.parameter
+func (m *Modular) Parameters() []Parameter {
+	if m.st == nil {
+		return nil // no structure, see NewModular
+	}
+	var ps []Parameter
+	for _, s := range m.st.Stores {
+		if s.Hi > 0. {
+			ps = append(ps, Parameter{s.Name + ".cap", "m", s.Name + " store capacity", 0., inf, s.Lo, s.Hi, false})
+		}
+	}
+	for _, f := range m.st.Fluxes {
+		if f.Eq == nil {
+			continue // reported by New
+		}
+		for _, p := range f.Eq.Parameters() {
+			p.Name = f.Name + "." + p.Name
+			p.Desc += " (" + f.Name + ")"
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// Entry returns a registry entry of the structure, sampled over its default parameter ranges,
+// such that it can be calibrated by name once registered:
+//
+//	rr.Register("mystructure", st.Entry())
+func (st *Structure) Entry() Entry {
+	ps := NewModular(st).Parameters()
+	return Entry{
+		New: func() Model { return NewModular(st) },
+		Sample: func(u []float64) []float64 {
+			p := make([]float64, len(ps))
+			for i, par := range ps {
+				p[i] = par.Sample(u[i])
+			}
+			return p
+		},
+		Ndim: len(ps),
+	}
+}
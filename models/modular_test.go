@@ -0,0 +1,69 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// bucket is an HBV-like structure: a soil store contributing a fraction of rainfall, overflowing
+// when full and percolating to a groundwater store
+func bucket() *Structure {
+	return &Structure{
+		Stores: []Store{{Name: "soil", Lo: .05, Hi: .5}, {Name: "gw", GW: true}},
+		Fluxes: []Flux{
+			{Name: "qs", From: SourceRain, To: SinkOutlet, On: "soil", Eq: Beta{}},
+			{Name: "et", From: "soil", To: SinkET, Eq: Evaporation{}},
+			{Name: "perc", From: "soil", To: "gw", Eq: Linear{}},
+			{Name: "over", From: "soil", To: SinkOutlet, Eq: Overflow{}},
+			{Name: "qb", From: "gw", To: SinkOutlet, Eq: Linear{}},
+		},
+		Rain: "soil",
+	}
+}
+
+func TestModular(t *testing.T) {
+	perc := .05 * (1. - math.Exp(-1e-6*secPerDay))
+	// p [soil.cap, qs.beta, et.lp, perc.k, qb.k], stores [soil, gw]
+	checkSteps(t, func() Lumper { return NewModular(bucket()) }, &Dataset{Timestep: secPerDay}, []step{
+		{"contributing fraction and evaporation", []float64{.1, 1., 1., 0., 0.}, State{S: []float64{.05, 0.}}, .02, .004, .004 * .6, .01, 0., []float64{.05 + .01 - .004*.6, 0.}},
+		{"overflow", []float64{.1, 1., 1., 0., 0.}, State{S: []float64{.05, 0.}}, .15, 0., 0., .1, 0., []float64{.1, 0.}},
+		{"percolation", []float64{.1, 1., 1., 1e-6, 0.}, State{S: []float64{.05, 0.}}, 0., 0., 0., 0., perc, []float64{.05 - perc, perc}},
+	})
+}
+
+func TestModularClosure(t *testing.T) {
+	for _, ts := range []float64{secPerDay, 3600.} {
+		t.Run(fmt.Sprintf("%.0fs", ts), func(t *testing.T) {
+			m := NewModular(bucket())
+			closes(t, m, synthetic(400, ts), mid(m.Parameters())...)
+		})
+	}
+}
+
+func TestModularStructure(t *testing.T) {
+	if ps := (&Modular{}).Parameters(); ps != nil {
+		t.Errorf("zero-value Modular: got parameters %v", ps)
+	}
+	if err := (&Modular{}).New(&Dataset{}); err == nil {
+		t.Error("zero-value Modular: expected an error")
+	}
+	for _, c := range []struct {
+		name string
+		edit func(st *Structure)
+	}{
+		{"duplicate store", func(st *Structure) { st.Stores[1].Name = "soil" }},
+		{"unknown store", func(st *Structure) { st.Fluxes[2].To = "lz" }},
+		{"rain to a sink", func(st *Structure) { st.Rain = SinkOutlet }},
+		{"missing equation", func(st *Structure) { st.Fluxes[1].Eq = nil }},
+		{"power drainage of an unbounded store", func(st *Structure) { st.Fluxes[4].Eq = Power{} }},
+		{"saturation excess of rainfall", func(st *Structure) { st.Fluxes[0].On = "" }},
+	} {
+		st := bucket()
+		c.edit(st)
+		m := NewModular(st)
+		if err := m.New(&Dataset{}, mid(m.Parameters())...); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package rainrun
+
+import (
+	"fmt"
+	"time"
+)
+
+const secPerMonth = 365.25 / 12. * secPerDay // mean month length [s]
+
+// Monthly is implemented by models that run on monthly timesteps only
+type Monthly interface {
+	Lumper
+	Monthly()
+}
+
+// IsMonthly returns true for datasets of monthly timesteps
+func (ds *Dataset) IsMonthly() bool {
+	return ds.tsec() >= 28.*secPerDay
+}
+
+// ToMonthly returns a new Dataset of the forcings summed to monthly totals. Incomplete months
+// at either end of the record are dropped; missing or incomplete months within it return an error.
+// Forcings are assumed to be depths per timestep, as in the [yield, demand, obs] layout of Lumpers
+func (ds *Dataset) ToMonthly() (*Dataset, error) {
+	type month struct {
+		t0       time.Time
+		v        []float64
+		complete bool
+	}
+	ts := ds.tsec()
+	var ms []month
+	n := 0
+	flush := func() {
+		if n == 0 {
+			return
+		}
+		m := &ms[len(ms)-1]
+		ndays := m.t0.AddDate(0, 1, 0).Sub(m.t0).Hours() / 24.
+		m.complete = float64(n)*ts >= ndays*secPerDay-ts/2.
+	}
+	for i, t := range ds.DT {
+		m0 := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		if n == 0 || !m0.Equal(ms[len(ms)-1].t0) {
+			flush()
+			ms, n = append(ms, month{t0: m0, v: make([]float64, len(ds.FRC[i]))}), 0
+		}
+		v := ms[len(ms)-1].v
+		for k, x := range ds.FRC[i] {
+			v[k] += x
+		}
+		n++
+	}
+	flush()
+
+	// trim partial months at either end
+	for len(ms) > 0 && !ms[0].complete {
+		ms = ms[1:]
+	}
+	for len(ms) > 0 && !ms[len(ms)-1].complete {
+		ms = ms[:len(ms)-1]
+	}
+	if len(ms) == 0 {
+		return nil, fmt.Errorf("ToMonthly: the dataset does not hold a complete month")
+	}
+
+	mds := Dataset{HDR: ds.HDR, Timestep: secPerMonth, Loc: ds.Loc, UTMZone: ds.UTMZone}
+	for i, m := range ms {
+		if !m.complete {
+			return nil, fmt.Errorf("ToMonthly: incomplete month %s", m.t0.Format("2006-01"))
+		}
+		if i > 0 && !m.t0.Equal(ms[i-1].t0.AddDate(0, 1, 0)) {
+			return nil, fmt.Errorf("ToMonthly: missing month following %s", ms[i-1].t0.Format("2006-01"))
+		}
+		mds.DT = append(mds.DT, m.t0)
+		mds.FRC = append(mds.FRC, m.v)
+	}
+
+	mds.Ndt = len(mds.DT)
+	mds.DOY = make([]int, mds.Ndt)
+	for i, t := range mds.DT {
+		mds.DOY[i] = t.YearDay()
+	}
+	return &mds, nil
+}
@@ -0,0 +1,96 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// daily returns synthetic daily forcings dated from t0
+func daily(n int, t0 time.Time) *Dataset {
+	ds := synthetic(n, secPerDay)
+	ds.DT = make([]time.Time, n)
+	for i := range ds.DT {
+		ds.DT[i] = t0.AddDate(0, 0, i)
+	}
+	return ds
+}
+
+func TestToMonthly(t *testing.T) {
+	ds := daily(86, time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC)) // Jan 15 to Apr 10
+	mds, err := ds.ToMonthly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mds.IsMonthly() || ds.IsMonthly() {
+		t.Fatal("IsMonthly does not distinguish the monthly dataset")
+	}
+	if mds.Ndt != 2 || mds.DT[0].Month() != time.February || mds.DT[1].Month() != time.March {
+		t.Fatalf("got months %v, want February and March only", mds.DT)
+	}
+	for i, d := range [][2]int{{17, 45}, {45, 76}} { // day offsets of each month
+		for k := range mds.FRC[i] {
+			s := 0.
+			for j := d[0]; j < d[1]; j++ {
+				s += ds.FRC[j][k]
+			}
+			if math.Abs(mds.FRC[i][k]-s) > 1e-12 {
+				t.Errorf("%s forcing %d: got %g, want %g", mds.DT[i].Month(), k, mds.FRC[i][k], s)
+			}
+		}
+	}
+}
+
+// TestToMonthlyGaps drops a day, then a month, from within the record
+func TestToMonthlyGaps(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		i0, i1 int // days removed
+	}{
+		{"incomplete February", 40, 41},
+		{"missing February", 31, 59},
+	} {
+		ds := daily(90, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)) // January to March
+		ds.DT = append(ds.DT[:c.i0], ds.DT[c.i1:]...)
+		ds.FRC = append(ds.FRC[:c.i0], ds.FRC[c.i1:]...)
+		ds.Ndt = len(ds.DT)
+		if _, err := ds.ToMonthly(); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
+
+func TestMonthlyClosure(t *testing.T) {
+	ds, err := daily(3650, time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)).ToMonthly()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name string
+		m    Monthly
+		p    []float64
+	}{
+		{"GR2M", &GR2M{}, []float64{.4, .8}},
+		{"GR2M imports", &GR2M{}, []float64{.4, 1.2}},
+		{"ABCD", &ABCD{}, []float64{.98, .3, .4, .2}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			closes(t, c.m, ds, c.p...)
+		})
+	}
+	if err := (&GR2M{}).New(synthetic(10, secPerDay), .4, .8); err == nil {
+		t.Error("GR2M: expected an error for daily timesteps")
+	}
+}
+
+func TestMonthlyUpdate(t *testing.T) {
+	ds := &Dataset{Timestep: secPerMonth}
+	perc := .25 - .25/math.Cbrt(1.125) // GR2M percolation from a half-full store of x1 = .5
+	checkSteps(t, func() Lumper { return &GR2M{} }, ds, []step{
+		{"GR2M percolation", []float64{.5, 1.}, State{S: []float64{.25, 0.}}, 0., 0., 0., perc * perc / (perc + .06), 0., []float64{.25 - perc, perc * .06 / (perc + .06)}},
+		{"GR2M exchange", []float64{.5, .5}, State{S: []float64{0., .02}}, 0., 0., 0., .01 * .01 / .07, .01, []float64{0., .01 - .01*.01/.07}},
+	})
+	checkSteps(t, func() Lumper { return &ABCD{} }, ds, []step{
+		{"ABCD", []float64{1., .2, .5, .1}, State{}, .3, .1, .2 - .2*math.Exp(-.5), .05 + .005/1.1, .05, []float64{.2 * math.Exp(-.5), .05 / 1.1}},
+	})
+}
@@ -10,13 +10,17 @@ import (
 type MultiLayerCapacitance struct {
 	s1, s2, s3            res
 	a1, a2, a3, b, cv, fc float64
+	fts                   float64 // timestep as a fraction of a day; drainage is defined daily
 }
 
 // New MultiLayerCapacitance constructor
 // [coverDens, szDepth, porosity, fc, a, b, l1, l2, l3]
-func (m *MultiLayerCapacitance) New(p ...float64) {
-	if p[6]+p[7]+p[8] != 1. || fracCheck(p[0]) || p[3] < 0. || p[3] > p[2] || p[2] > 0. {
-		panic("MultiLayerCapacitance input error")
+func (m *MultiLayerCapacitance) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("MultiLayerCapacitance", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if math.Abs(p[6]+p[7]+p[8]-1.) > mingtzero || fracCheck(p[0]) || p[3] < 0. || p[3] > p[2] || p[2] <= 0. {
+		return &ParameterError{"MultiLayerCapacitance", "layer fractions must sum to 1, coverDens [0,1], 0 <= fc <= porosity, porosity > 0", p}
 	}
 	m.cv = p[0]         // fraction vegetation cover
 	m.fc = p[3] / p[2]  // fraction tension storage
@@ -28,14 +32,20 @@ func (m *MultiLayerCapacitance) New(p ...float64) {
 	m.a2 = p[7] * p[4]
 	m.a3 = p[8] * p[4]
 	m.b = 1. / p[5]
+	m.fts = ds.tsec() / secPerDay
+	return nil
 }
 
-// Update state for daily inputs
+// Update state
 func (m *MultiLayerCapacitance) Update(p, ep float64) (float64, float64, float64) {
-	var q float64
+	e1, e2, q, g := m.update(p, ep)
+	return e1 + e2, q, g
+}
+
+func (m *MultiLayerCapacitance) update(p, ep float64) (e1, e2, q, g float64) {
 	// layer 1
-	g := math.Pow(((m.s1.sto - m.s1.cap*m.fc) / m.a1), m.b)
-	e1 := (1.-m.cv)*ep*m.s1.storageFraction() - m.cv*ep*math.Min(m.s1.sto, m.s1.cap*m.fc)
+	g = m.drain(m.s1, m.a1)
+	e1 = (1.-m.cv)*ep*m.s1.storageFraction() - m.cv*ep*math.Min(m.s1.sto, m.s1.cap*m.fc)
 	s1n := m.s1.sto + p - e1/(m.s1.cap+m.s2.cap)/m.fc - g
 	if s1n > m.s1.cap {
 		q = s1n - m.s1.cap
@@ -43,11 +53,11 @@ func (m *MultiLayerCapacitance) Update(p, ep float64) (float64, float64, float64
 	}
 
 	// layer 2
-	var s2n, e2 float64
+	var s2n float64
 	if m.s2.cap > 0. {
-		g = math.Pow(((m.s2.sto - m.s2.cap*m.fc) / m.a2), m.b)
+		g = m.drain(m.s2, m.a2)
 		e2 = m.cv * ep * math.Min(m.s2.sto, m.s2.cap*m.fc)
-		s2n = m.s2.sto - e2/(m.s1.cap+m.s2.cap)/m.fc + math.Pow(((m.s1.sto-m.s1.cap*m.fc)/m.a1), m.b) - g
+		s2n = m.s2.sto - e2/(m.s1.cap+m.s2.cap)/m.fc + m.drain(m.s1, m.a1) - g
 		if s2n > m.s2.cap {
 			q += s2n - m.s2.cap
 			s2n = m.s2.cap
@@ -57,18 +67,32 @@ func (m *MultiLayerCapacitance) Update(p, ep float64) (float64, float64, float64
 	// layer 3
 	var s3n float64
 	if m.s3.cap > 0. {
-		g = math.Pow(((m.s3.sto - m.s3.cap*m.fc) / m.a3), m.b)
-		s3n = m.s3.sto + math.Pow(((m.s2.sto-m.s2.cap*m.fc)/m.a2), m.b) - g
+		g = m.drain(m.s3, m.a3)
+		s3n = m.s3.sto + m.drain(m.s2, m.a2) - g
 		if s3n > m.s3.cap {
 			q += s3n - m.s3.cap
 			s3n = m.s3.cap
 		}
 	}
-	a := e1 + e2
 	m.s1.sto = s1n
 	m.s2.sto = s2n
 	m.s3.sto = s3n
-	return a, q, g
+	return
+}
+
+// drain returns the layer drainage over the timestep
+func (m *MultiLayerCapacitance) drain(s res, a float64) float64 {
+	return math.Pow((s.sto-s.cap*m.fc)/a, m.b) * m.fts
+}
+
+// UpdateFluxes updates state, returning evaporation from layers 1 and 2 (e1, e2)
+func (m *MultiLayerCapacitance) UpdateFluxes(p, ep float64) Fluxes {
+	e1, e2, q, g := m.update(p, ep)
+	return Fluxes{
+		AET: e1 + e2, Runoff: q, Recharge: g,
+		Q: map[string]float64{"e1": e1, "e2": e2},
+		S: map[string]float64{"s1": m.s1.sto, "s2": m.s2.sto, "s3": m.s3.sto},
+	}
 }
 
 // Storage returns total storage
@@ -76,6 +100,37 @@ func (m *MultiLayerCapacitance) Storage() float64 {
 	return m.s1.sto + m.s2.sto + m.s3.sto
 }
 
+// State returns the current state [s1, s2, s3]
+func (m *MultiLayerCapacitance) State() State {
+	return State{S: []float64{m.s1.sto, m.s2.sto, m.s3.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *MultiLayerCapacitance) SetState(s State) error {
+	if err := s.check("MultiLayerCapacitance", 3, 0); err != nil {
+		return err
+	}
+	m.s1.sto = s.S[0]
+	m.s2.sto = s.S[1]
+	m.s3.sto = s.S[2]
+	return nil
+}
+
+// Parameters describes the MultiLayerCapacitance model parameters
+func (m *MultiLayerCapacitance) Parameters() []Parameter {
+	return []Parameter{
+		{"coverDens", "-", "fraction vegetation cover", 0., 1., 0., 1., false},
+		{"szDepth", "mm", "soil zone depth", 0., inf, 0., 1000., false},
+		{"porosity", "-", "soil porosity; sampled jointly with fc", 0., 1., 0., .3, false},
+		{"fc", "-", "field capacity; sampled jointly with porosity", 0., 1., 0., .3, false},
+		{"a", "-", "drainage coefficient (daily)", 0., inf, 0., 100., false},
+		{"b", "-", "drainage exponent", 0., 1., 0., 1., false},
+		{"l1", "-", "fraction of soil zone in layer 1; l1+l2+l3=1", 0., 1., 0., 1., false},
+		{"l2", "-", "fraction of soil zone in layer 2; l1+l2+l3=1", 0., 1., 0., 1., false},
+		{"l3", "-", "fraction of soil zone in layer 3; l1+l2+l3=1", 0., 1., 0., 1., false},
+	}
+}
+
 // // SampleSpace returns a hypercube from which the optimum resides
 // func (m *MultiLayerCapacitance) SampleSpace(u []float64) []float64 {
 // 	const sd, n = 1000.0, 0.3
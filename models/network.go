@@ -0,0 +1,134 @@
+package rainrun
+
+import (
+	"fmt"
+
+	"github.com/maseology/rainrun/routing"
+)
+
+// Node is a subcatchment of a semi-distributed network
+type Node struct {
+	ID    int                // node identifier
+	M     Model              // a Lumper or a Climater, already constructed with New
+	DS    *Dataset           // subcatchment forcings
+	Area  float64            // local (non-nested) subcatchment area [m²]
+	Dn    int                // ID of the node downstream, negative for the outlet
+	Reach *routing.Muskingum // reach to the downstream node, nil for none
+}
+
+// Network of subcatchments, with nodes linked to their downstream node by routing reaches
+type Network struct {
+	Nodes []Node
+	ord   []int // node indices ordered from upstream to downstream
+	dn    []int // downstream node index, -1 for none
+}
+
+// NewNetwork checks and orders the subcatchment network
+func NewNetwork(nodes []Node) (*Network, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("NewNetwork error: no nodes")
+	}
+	ix := make(map[int]int, len(nodes))
+	for i, n := range nodes {
+		if _, ok := ix[n.ID]; ok {
+			return nil, fmt.Errorf("NewNetwork error: duplicate node ID %d", n.ID)
+		}
+		if n.M == nil || n.DS == nil {
+			return nil, fmt.Errorf("NewNetwork error: node %d missing a model or dataset", n.ID)
+		}
+		if n.Area <= 0. {
+			return nil, fmt.Errorf("NewNetwork error: node %d area must be > 0", n.ID)
+		}
+		if ds0 := nodes[0].DS; n.DS.Ndt != ds0.Ndt || n.DS.tsec() != ds0.tsec() || len(n.DS.DT) != len(ds0.DT) || len(n.DS.DT) > 0 && !n.DS.DT[0].Equal(ds0.DT[0]) {
+			return nil, fmt.Errorf("NewNetwork error: node %d dataset does not match the timesteps of node %d", n.ID, nodes[0].ID)
+		}
+		if n.Reach != nil && n.Reach.Timestep() != n.DS.tsec() {
+			return nil, fmt.Errorf("NewNetwork error: node %d reach is routed at a timestep of %gs, its dataset at %gs", n.ID, n.Reach.Timestep(), n.DS.tsec())
+		}
+		ix[n.ID] = i
+	}
+
+	// topological sort, upstream nodes first
+	nw := Network{Nodes: nodes, ord: make([]int, 0, len(nodes)), dn: make([]int, len(nodes))}
+	nup := make([]int, len(nodes))
+	for i, n := range nodes {
+		nw.dn[i] = -1
+		if n.Dn < 0 {
+			continue
+		}
+		j, ok := ix[n.Dn]
+		if !ok {
+			return nil, fmt.Errorf("NewNetwork error: node %d drains to unknown node %d", n.ID, n.Dn)
+		}
+		nw.dn[i] = j
+		nup[j]++
+	}
+	for i := range nodes {
+		if nup[i] == 0 {
+			nw.ord = append(nw.ord, i)
+		}
+	}
+	for k := 0; k < len(nw.ord); k++ {
+		if j := nw.dn[nw.ord[k]]; j >= 0 {
+			if nup[j]--; nup[j] == 0 {
+				nw.ord = append(nw.ord, j)
+			}
+		}
+	}
+	if len(nw.ord) != len(nodes) {
+		return nil, fmt.Errorf("NewNetwork error: network contains a cycle")
+	}
+	return &nw, nil
+}
+
+// Run the network, returning the discharge [m³/s] at each node, keyed by node ID, per timestep.
+// Node discharge is the local subcatchment runoff [m/ts] scaled by its area, plus the inflows
+// routed from the nodes upstream.
+func (nw *Network) Run() (map[int][]float64, error) {
+	ds0 := nw.Nodes[0].DS
+	ts := ds0.tsec()
+	q, qin := make([][]float64, len(nw.Nodes)), make([]float64, len(nw.Nodes))
+	for i := range q {
+		q[i] = make([]float64, ds0.Ndt)
+	}
+	for k := 0; k < ds0.Ndt; k++ {
+		for i := range qin {
+			qin[i] = 0.
+		}
+		for _, i := range nw.ord {
+			n := nw.Nodes[i]
+			_, _, r, _ := Step(n.DS, n.M, k)
+			q[i][k] = qin[i] + r*n.Area/ts
+			if j := nw.dn[i]; j >= 0 {
+				if n.Reach != nil {
+					qin[j] += n.Reach.Update(q[i][k])
+				} else {
+					qin[j] += q[i][k]
+				}
+			}
+		}
+	}
+
+	o := make(map[int][]float64, len(nw.Nodes))
+	for i, n := range nw.Nodes {
+		o[n.ID] = q[i]
+	}
+	for _, n := range nw.Nodes {
+		if err := UpdateError(n.M); err != nil {
+			return o, fmt.Errorf("Network.Run error at node %d: %v", n.ID, err)
+		}
+	}
+	return o, nil
+}
+
+// Storage returns the total water held in the network's subcatchments and reaches [m³]
+func (nw *Network) Storage() float64 {
+	s := 0.
+	for _, n := range nw.Nodes {
+		s += n.M.Storage() * n.Area
+		if n.Reach != nil {
+			s += n.Reach.Storage()
+		}
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maseology/rainrun/routing"
+)
+
+func TestNetwork(t *testing.T) {
+	ds, p := daily(300, time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)), mid((&HBV{}).Parameters())
+	hbv := func() *HBV {
+		m := &HBV{}
+		if err := m.New(ds, p...); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	reach := func(k float64) *routing.Muskingum {
+		r, err := routing.NewMuskingum(k, .2, secPerDay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	// two headwaters joining upstream of the outlet, listed outlet first
+	area := map[int]float64{1: 2e7, 2: 3e7, 3: 1e7}
+	nw, err := NewNetwork([]Node{
+		{ID: 3, M: hbv(), DS: ds, Area: area[3], Dn: -1},
+		{ID: 1, M: hbv(), DS: ds, Area: area[1], Dn: 3, Reach: reach(7200.)},
+		{ID: 2, M: hbv(), DS: ds, Area: area[2], Dn: 3, Reach: reach(3. * 3600.)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := nw.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every node's local runoff volume leaves the outlet or remains in the reaches
+	vin, vout := 0., 0.
+	ms := map[int]*HBV{1: hbv(), 2: hbv(), 3: hbv()}
+	for k, v := range ds.FRC {
+		for id, m := range ms {
+			_, r, _ := m.Update(v[0], v[1])
+			vin += r * area[id]
+			if id != 3 && q[id][k] != r*area[id]/secPerDay {
+				t.Fatalf("headwater %d step %d: got %g, want the local runoff %g", id, k+1, q[id][k], r*area[id]/secPerDay)
+			}
+		}
+		vout += q[3][k] * secPerDay
+	}
+	rs := nw.Nodes[1].Reach.Storage() + nw.Nodes[2].Reach.Storage()
+	if math.Abs(vin-vout-rs) > 1e-6*vin {
+		t.Errorf("outlet volume %g m³ and reach storage %g m³ do not close the runoff volume %g m³", vout, rs, vin)
+	}
+}
+
+func TestNewNetwork(t *testing.T) {
+	t0 := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	ds, late := daily(30, t0), daily(30, t0.AddDate(0, 0, 1))
+	node := func(id, dn int, ds *Dataset) Node {
+		m := &GR4J{}
+		if err := m.New(ds, mid(m.Parameters())...); err != nil {
+			t.Fatal(err)
+		}
+		return Node{ID: id, M: m, DS: ds, Area: 1e6, Dn: dn}
+	}
+	hourly := func(n Node) Node {
+		r, err := routing.NewMuskingum(3600., .2, 3600.)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.Reach = r
+		return n
+	}
+	for _, c := range []struct {
+		name  string
+		nodes []Node
+	}{
+		{"no nodes", nil},
+		{"cycle", []Node{node(1, 2, ds), node(2, 3, ds), node(3, 1, ds), node(4, -1, ds)}},
+		{"unknown downstream node", []Node{node(1, 5, ds), node(2, -1, ds)}},
+		{"duplicate ID", []Node{node(1, 2, ds), node(1, -1, ds)}},
+		{"zero area", []Node{{ID: 1, M: &GR4J{}, DS: ds, Dn: -1}}},
+		{"misaligned start dates", []Node{node(1, 2, ds), node(2, -1, late)}},
+		{"hourly reach", []Node{hourly(node(1, 2, ds)), node(2, -1, ds)}},
+	} {
+		if _, err := NewNetwork(c.nodes); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package rainrun
+
+import "math"
+
+// PDM Probability Distributed Model: Pareto-distributed store capacities, threshold drainage to a slow store,
+// direct runoff routed through a cascade of two linear reservoirs
+// Moore, R.J., 1985. The probability-distributed principle and runoff production at point and basin scales. Hydrological Sciences Journal 30(2). pp. 273-297.
+// Moore, R.J., 2007. The PDM rainfall-runoff model. Hydrology and Earth System Sciences 11(1). pp. 483-499.
+type PDM struct {
+	s                   float64 // soil moisture store
+	fst                 [2]res  // fast cascade
+	slw                 res     // slow store
+	cmin, cmax, smax, b float64
+	be, kg, bg, st      float64
+}
+
+// New PDM constructor
+// [cmin, cmax, b, be, kg, bg, st, k1, k2, kb]
+func (m *PDM) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("PDM", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if p[0] < 0. || p[1] <= p[0] || p[2] < 0. || p[3] <= 0. || p[4] < 0. || p[5] < 0. || p[6] < 0. || p[7] < 0. || p[8] < 0. || p[9] < 0. {
+		return &ParameterError{"PDM", "cmax must be > cmin >= 0, be > 0, all other parameters >= 0", p}
+	}
+	ts := ds.tsec()
+	m.cmin, m.cmax, m.b = p[0], p[1], p[2]      // minimum and maximum store capacities, Pareto exponent
+	m.smax = (m.b*m.cmin + m.cmax) / (m.b + 1.) // maximum basin storage
+	m.be = p[3]                                 // evapotranspiration exponent
+	m.kg = p[4] * ts                            // drainage rate per step [1/ts]
+	m.bg, m.st = p[5], p[6]                     // drainage exponent and threshold storage
+	m.fst[0].new(math.MaxFloat64, recession(p[7], ts))
+	m.fst[1].new(math.MaxFloat64, recession(p[8], ts))
+	m.slw.new(math.MaxFloat64, recession(p[9], ts))
+	m.s, m.fst[0].sto, m.fst[1].sto, m.slw.sto = 0., 0., 0., 0.
+	return nil
+}
+
+// Update state
+func (m *PDM) Update(p, ep float64) (float64, float64, float64) {
+	a, qf, qs, g := m.update(p, ep)
+	return a, qf + qs, g
+}
+
+// UpdateFluxes updates state, returning fast (qf) and slow (qs) flow
+func (m *PDM) UpdateFluxes(p, ep float64) Fluxes {
+	a, qf, qs, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qf + qs, Recharge: g,
+		Q: map[string]float64{"qf": qf, "qs": qs},
+		S: map[string]float64{"s": m.s, "f1": m.fst[0].sto, "f2": m.fst[1].sto, "slw": m.slw.sto},
+	}
+}
+
+func (m *PDM) update(p, ep float64) (a, qf, qs, g float64) {
+	// drainage to the slow store
+	if m.s > m.st {
+		g = math.Min(m.kg*m.smax*math.Pow((m.s-m.st)/m.smax, m.bg), m.s-m.st)
+	}
+
+	// evapotranspiration
+	a = math.Min(ep*(1.-math.Pow((m.smax-m.s)/m.smax, m.be)), m.s-g+p)
+
+	// direct runoff from the store capacities filled
+	pi := p - a - g
+	var v float64
+	if pi > 0. {
+		s1 := m.storage(math.Min(m.critical(m.s)+pi, m.cmax))
+		v = math.Max(pi-(s1-m.s), 0.)
+		m.s += pi - v
+	} else {
+		m.s = math.Max(m.s+pi, 0.)
+	}
+
+	m.fst[0].update(v)
+	m.fst[1].update(m.fst[0].decayExp())
+	qf = m.fst[1].decayExp()
+	m.slw.update(g)
+	qs = m.slw.decayExp()
+	return
+}
+
+// critical returns the critical capacity, below which all stores are full, given basin storage s
+func (m *PDM) critical(s float64) float64 {
+	if s <= m.cmin {
+		return s
+	}
+	return m.cmax - (m.cmax-m.cmin)*math.Pow(math.Max((m.smax-s)/(m.smax-m.cmin), 0.), 1./(m.b+1.))
+}
+
+// storage returns the basin storage given the critical capacity c
+func (m *PDM) storage(c float64) float64 {
+	if c <= m.cmin {
+		return c
+	}
+	return m.cmin + (m.smax-m.cmin)*(1.-math.Pow((m.cmax-c)/(m.cmax-m.cmin), m.b+1.))
+}
+
+// Storage returns total storage
+func (m *PDM) Storage() float64 {
+	return m.s + m.fst[0].sto + m.fst[1].sto + m.slw.sto
+}
+
+// State returns the current state [s, f1, f2, slw]
+func (m *PDM) State() State {
+	return State{S: []float64{m.s, m.fst[0].sto, m.fst[1].sto, m.slw.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *PDM) SetState(s State) error {
+	if err := s.check("PDM", 4, 0); err != nil {
+		return err
+	}
+	m.s, m.fst[0].sto, m.fst[1].sto, m.slw.sto = s.S[0], s.S[1], s.S[2], s.S[3]
+	return nil
+}
+
+// Parameters describes the PDM parameters
+func (m *PDM) Parameters() []Parameter {
+	return []Parameter{
+		{"cmin", "m", "minimum store capacity", 0., inf, 0., .1, false},
+		{"cmax", "m", "maximum store capacity", 0., inf, .1, .6, false},
+		{"b", "-", "Pareto distribution exponent of store capacities", 0., inf, .1, 2., false},
+		{"be", "-", "evapotranspiration function exponent", 0., inf, 1., 3., false},
+		{"kg", "1/s", "drainage rate, at full storage above threshold", 0., inf, 1e-8, 1e-5, true},
+		{"bg", "-", "drainage function exponent", 0., inf, 1., 3., false},
+		{"st", "m", "threshold storage for drainage", 0., inf, 0., .05, false},
+		{"k1", "1/s", "first fast cascade reservoir recession rate", 0., inf, 1e-6, 3e-5, true},
+		{"k2", "1/s", "second fast cascade reservoir recession rate", 0., inf, 1e-6, 3e-5, true},
+		{"kb", "1/s", "slow store recession rate", 0., inf, 1e-8, 1e-6, true},
+	}
+}
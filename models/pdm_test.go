@@ -0,0 +1,17 @@
+package rainrun
+
+import "testing"
+
+func TestPDM(t *testing.T) {
+	// [cmin, cmax, b, be, kg, bg, st, k1, k2, kb], with a fast cascade passing all runoff within the step
+	par := func(b, kg float64) []float64 {
+		return []float64{0., .1, b, 1., kg, 1., .01, 1., 1., 0.}
+	}
+	// stores [s, ..]
+	checkSteps(t, func() Lumper { return &PDM{} }, &Dataset{Timestep: secPerDay}, []step{
+		{"uniform capacity spills", par(0., 0.), State{S: []float64{.09}}, .03, 0., 0., .02, 0., []float64{.1}},
+		{"evaporation scales with deficit", par(0., 0.), State{S: []float64{.05}}, 0., .004, .002, 0., 0., []float64{.048}},
+		{"drainage above threshold", par(0., 1e-6), State{S: []float64{.06}}, 0., 0., 0., 0., 1e-6 * secPerDay * .1 * .5, nil},
+		{"pareto capacities on empty", par(1., 0.), State{S: []float64{0.}}, .02, 0., 0., .02 - .05*(1.-.8*.8), 0., nil},
+	})
+}
@@ -0,0 +1,313 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/maseology/goHydro/pet"
+	"github.com/maseology/goHydro/solirrad"
+)
+
+// PET : interface to potential evapotranspiration modules, returning PET [m] over the timestep;
+// daily PET is apportioned evenly over sub-daily timesteps
+// v: [tmax, tmin, rain, snow]
+type PET interface {
+	Describer
+	New(ds *Dataset, p ...float64) error
+	Update(v []float64, doy int) float64
+}
+
+// Makkink PET module, with global radiation from the Bristow-Campbell relationship
+// [alpha, beta]
+type Makkink struct {
+	SI          *solirrad.SolIrad // set by New from the dataset location
+	Alpha, Beta float64
+	fd          float64 // timestep [d]
+}
+
+// New Makkink constructor
+func (e *Makkink) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("Makkink", e.Parameters(), p); err != nil {
+		return err
+	}
+	e.Alpha, e.Beta = p[0], p[1]
+	e.fd = ds.tsec() / secPerDay
+	var err error
+	e.SI, err = ds.SolIrad()
+	return err
+}
+
+// Update returns PET
+func (e *Makkink) Update(v []float64, doy int) float64 {
+	const (
+		pres = 101300.
+		a    = 0.75
+		b    = 0.0025
+		c    = 2.5
+	)
+	tx, tn := v[0], v[1]
+	tm := (tx + tn) / 2.
+	Kg := e.SI.GlobalFromPotential(tx, tn, a, b, c, doy)
+	return pet.Makkink(Kg, tm, pres, e.Alpha, e.Beta) * e.fd
+}
+
+// Parameters describes the Makkink parameters
+func (e *Makkink) Parameters() []Parameter {
+	return MakkinkParameters()
+}
+
+// HargreavesSamani PET module
+// Hargreaves, G.H., Z.A. Samani, 1985. Reference crop evapotranspiration from temperature. Applied Engineering in Agriculture 1(2). pp. 96-99.
+// [c]
+type HargreavesSamani struct {
+	C       float64
+	lat, fd float64 // latitude [rad]; timestep [d]
+}
+
+// New HargreavesSamani constructor
+func (e *HargreavesSamani) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("HargreavesSamani", e.Parameters(), p); err != nil {
+		return err
+	}
+	e.C = p[0]
+	e.fd = ds.tsec() / secPerDay
+	var err error
+	e.lat, err = latitude(ds)
+	return err
+}
+
+// Update returns PET
+func (e *HargreavesSamani) Update(v []float64, doy int) float64 {
+	tx, tn := v[0], v[1]
+	ra := extraterrestrial(e.lat, doy)
+	return e.C * 0.408 * ra * ((tx+tn)/2. + 17.8) * math.Sqrt(math.Max(tx-tn, 0.)) / 1000. * e.fd
+}
+
+// Parameters describes the HargreavesSamani parameters
+func (e *HargreavesSamani) Parameters() []Parameter {
+	return []Parameter{
+		{"c", "-", "Hargreaves coefficient; 0.0023 in the original formulation", 0., inf, .0015, .0035, false},
+	}
+}
+
+// Oudin PET module
+// Oudin, L., F. Hervieu, C. Michel, C. Perrin, V. Andréassian, F. Anctil, C. Loumagne, 2005. Which potential evapotranspiration input for a lumped rainfall–runoff model? Part 2. Journal of Hydrology 303. pp. 290-306.
+// [k1, k2]
+type Oudin struct {
+	K1, K2  float64
+	lat, fd float64 // latitude [rad]; timestep [d]
+}
+
+// New Oudin constructor
+func (e *Oudin) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("Oudin", e.Parameters(), p); err != nil {
+		return err
+	}
+	if p[0] <= 0. {
+		return &ParameterError{"Oudin", "k1 must be > 0", p}
+	}
+	e.K1, e.K2 = p[0], p[1]
+	e.fd = ds.tsec() / secPerDay
+	var err error
+	e.lat, err = latitude(ds)
+	return err
+}
+
+// Update returns PET
+func (e *Oudin) Update(v []float64, doy int) float64 {
+	tm := (v[0] + v[1]) / 2.
+	if tm+e.K2 <= 0. {
+		return 0.
+	}
+	return extraterrestrial(e.lat, doy) / lambda * (tm + e.K2) / e.K1 / 1000. * e.fd
+}
+
+// Parameters describes the Oudin parameters
+func (e *Oudin) Parameters() []Parameter {
+	return []Parameter{
+		{"k1", "°C", "temperature scaling factor; 100 in the original formulation", 0., inf, 50., 150., false},
+		{"k2", "°C", "temperature threshold; 5 in the original formulation", -inf, inf, -5., 15., false},
+	}
+}
+
+// Hamon PET module
+// Hamon, W.R., 1961. Estimating potential evapotranspiration. Journal of the Hydraulics Division 87(3). pp. 107-120.
+// as given in: Lu, J., G. Sun, S.G. McNulty, D.M. Amatya, 2005. A comparison of six potential evapotranspiration methods for regional use in the southeastern United States. JAWRA 41(3). pp. 621-633.
+// [kpec]
+type Hamon struct {
+	K       float64
+	lat, fd float64 // latitude [rad]; timestep [d]
+}
+
+// New Hamon constructor
+func (e *Hamon) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("Hamon", e.Parameters(), p); err != nil {
+		return err
+	}
+	e.K = p[0]
+	e.fd = ds.tsec() / secPerDay
+	var err error
+	e.lat, err = latitude(ds)
+	return err
+}
+
+// Update returns PET
+func (e *Hamon) Update(v []float64, doy int) float64 {
+	tm := (v[0] + v[1]) / 2.
+	ld := daylight(e.lat, doy) / 12.                // daytime length [12h]
+	rhosat := 216.7 * 10. * esat(tm) / (tm + 273.3) // saturated vapour density [g/m³]
+	return 0.1651 * ld * rhosat * e.K / 1000. * e.fd
+}
+
+// Parameters describes the Hamon parameters
+func (e *Hamon) Parameters() []Parameter {
+	return []Parameter{
+		{"kpec", "-", "Hamon calibration coefficient; 1.2 in Lu et.al., (2005)", 0., inf, .5, 2., false},
+	}
+}
+
+// PriestleyTaylor PET module, with net radiation estimated from the daily temperature range (FAO-56)
+// Priestley, C.H.B., R.J. Taylor, 1972. On the assessment of surface heat flux and evaporation using large-scale parameters. Monthly Weather Review 100(2). pp. 81-92.
+// [alpha, krs]
+type PriestleyTaylor struct {
+	Alpha, Krs float64
+	lat, z, fd float64 // latitude [rad]; elevation [m]; timestep [d]
+}
+
+// New PriestleyTaylor constructor
+func (e *PriestleyTaylor) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("PriestleyTaylor", e.Parameters(), p); err != nil {
+		return err
+	}
+	e.Alpha, e.Krs = p[0], p[1]
+	e.z, e.fd = ds.Elevation(), ds.tsec()/secPerDay
+	var err error
+	e.lat, err = latitude(ds)
+	return err
+}
+
+// Update returns PET
+func (e *PriestleyTaylor) Update(v []float64, doy int) float64 {
+	tx, tn := v[0], v[1]
+	tm := (tx + tn) / 2.
+	rn := netRadiation(tx, tn, esat(tn), e.Krs, e.lat, e.z, doy)
+	d, g := slope(tm), psychrometric(e.z)
+	return math.Max(e.Alpha*d/(d+g)*rn/lambda/1000., 0.) * e.fd
+}
+
+// Parameters describes the PriestleyTaylor parameters
+func (e *PriestleyTaylor) Parameters() []Parameter {
+	return []Parameter{
+		{"alpha", "-", "Priestley-Taylor coefficient; 1.26 in the original formulation", 0., inf, .8, 1.8, false},
+		{"krs", "°C^-0.5", "radiation adjustment coefficient; 0.16 interior, 0.19 coastal", 0., inf, .1, .25, false},
+	}
+}
+
+// PenmanMonteith FAO-56 reference crop PET module, scaled by a crop coefficient.
+// Requires forcings of relative humidity and wind speed, following precipitation:
+// v: [tmax, tmin, rain, snow, rh (%), u2 (m/s), obs]
+// Allen, R.G., L.S. Pereira, D. Raes, M. Smith, 1998. Crop evapotranspiration: guidelines for computing crop water requirements. FAO Irrigation and drainage paper 56.
+// [kc, krs]
+type PenmanMonteith struct {
+	Kc, Krs    float64
+	lat, z, fd float64 // latitude [rad]; elevation [m]; timestep [d]
+}
+
+// New PenmanMonteith constructor
+func (e *PenmanMonteith) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("PenmanMonteith", e.Parameters(), p); err != nil {
+		return err
+	}
+	if len(ds.FRC) == 0 || len(ds.FRC[0]) < 7 {
+		return fmt.Errorf("PenmanMonteith: forcings [tmax, tmin, rain, snow, rh, u2, obs] do not carry humidity and wind speed")
+	}
+	e.Kc, e.Krs = p[0], p[1]
+	e.z, e.fd = ds.Elevation(), ds.tsec()/secPerDay
+	var err error
+	e.lat, err = latitude(ds)
+	return err
+}
+
+// Update returns PET
+func (e *PenmanMonteith) Update(v []float64, doy int) float64 {
+	tx, tn, rh, u2 := v[0], v[1], v[4], v[5]
+	tm := (tx + tn) / 2.
+	es := (esat(tx) + esat(tn)) / 2.
+	ea := rh / 100. * es
+	rn := netRadiation(tx, tn, ea, e.Krs, e.lat, e.z, doy)
+	d, g := slope(tm), psychrometric(e.z)
+	et0 := (0.408*d*rn + g*900./(tm+273.)*u2*(es-ea)) / (d + g*(1.+0.34*u2)) // [mm/d]
+	return math.Max(e.Kc*et0/1000., 0.) * e.fd
+}
+
+// Parameters describes the PenmanMonteith parameters
+func (e *PenmanMonteith) Parameters() []Parameter {
+	return []Parameter{
+		{"kc", "-", "crop coefficient", 0., inf, .5, 1.5, false},
+		{"krs", "°C^-0.5", "radiation adjustment coefficient; 0.16 interior, 0.19 coastal", 0., inf, .1, .25, false},
+	}
+}
+
+const (
+	lambda = 2.45     // latent heat of vaporization [MJ/kg]
+	gsc    = 0.0820   // solar constant [MJ/m²/min]
+	sigma  = 4.903e-9 // Stefan-Boltzmann constant [MJ/K⁴/m²/d]
+	albedo = 0.23     // reference crop albedo
+	degRad = math.Pi / 180.
+)
+
+// latitude returns the dataset latitude in radians
+func latitude(ds *Dataset) (float64, error) {
+	lat, err := ds.Latitude()
+	return lat * degRad, err
+}
+
+// sunset returns the solar declination and sunset hour angle [rad] (FAO-56 eq.24-25)
+func sunset(lat float64, doy int) (dec, ws float64) {
+	dec = 0.409 * math.Sin(2.*math.Pi*float64(doy)/365.-1.39)
+	ws = math.Acos(math.Max(-1., math.Min(1., -math.Tan(lat)*math.Tan(dec))))
+	return
+}
+
+// extraterrestrial returns the daily extraterrestrial radiation Ra [MJ/m²/d] (FAO-56 eq.21)
+func extraterrestrial(lat float64, doy int) float64 {
+	dr := 1. + 0.033*math.Cos(2.*math.Pi*float64(doy)/365.)
+	dec, ws := sunset(lat, doy)
+	return 24. * 60. / math.Pi * gsc * dr * (ws*math.Sin(lat)*math.Sin(dec) + math.Cos(lat)*math.Cos(dec)*math.Sin(ws))
+}
+
+// daylight returns the daylight hours (FAO-56 eq.34)
+func daylight(lat float64, doy int) float64 {
+	_, ws := sunset(lat, doy)
+	return 24. / math.Pi * ws
+}
+
+// esat returns the saturation vapour pressure [kPa] at temperature t [°C] (FAO-56 eq.11)
+func esat(t float64) float64 {
+	return 0.6108 * math.Exp(17.27*t/(t+237.3))
+}
+
+// slope returns the slope of the saturation vapour pressure curve [kPa/°C] (FAO-56 eq.13)
+func slope(t float64) float64 {
+	return 4098. * esat(t) / math.Pow(t+237.3, 2.)
+}
+
+// psychrometric returns the psychrometric constant [kPa/°C] at elevation z [m] (FAO-56 eq.7-8)
+func psychrometric(z float64) float64 {
+	return 0.665e-3 * 101.3 * math.Pow((293.-0.0065*z)/293., 5.26)
+}
+
+// netRadiation returns the daily net radiation [MJ/m²/d], with solar radiation
+// estimated from the temperature range and actual vapour pressure ea [kPa] (FAO-56 eq.37-40, 50)
+func netRadiation(tx, tn, ea, krs, lat, z float64, doy int) float64 {
+	ra := extraterrestrial(lat, doy)
+	rs := krs * math.Sqrt(math.Max(tx-tn, 0.)) * ra
+	rso := (0.75 + 2e-5*z) * ra
+	rns := (1. - albedo) * rs
+	fcd := 1.
+	if rso > 0. {
+		fcd = 1.35*math.Min(rs/rso, 1.) - 0.35
+	}
+	rnl := sigma * (math.Pow(tx+273.16, 4.) + math.Pow(tn+273.16, 4.)) / 2. * (0.34 - 0.14*math.Sqrt(ea)) * fcd
+	return rns - rnl
+}
@@ -0,0 +1,101 @@
+package rainrun
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFAO56(t *testing.T) {
+	lat := -20. * degRad // FAO-56 examples 8 and 9: 20°S, 3 September
+	for _, c := range []struct {
+		name      string
+		got, want float64
+		tol       float64
+	}{
+		{"Ra [MJ/m²/d]", extraterrestrial(lat, 246), 32.2, .05},
+		{"N [h]", daylight(lat, 246), 11.7, .05},
+		{"esat(25) [kPa]", esat(25.), 3.168, .001},
+		{"slope(25) [kPa/°C]", slope(25.), .189, .001},
+		{"psychrometric(1800) [kPa/°C]", psychrometric(1800.), .054, .001},
+		{"Hargreaves [m]", (&HargreavesSamani{C: .0023, lat: lat, fd: 1.}).Update([]float64{30., 20.}, 246), .00409, 1e-5},
+	} {
+		if math.Abs(c.got-c.want) > c.tol {
+			t.Errorf("%s: got %.4f, want %.4f", c.name, c.got, c.want)
+		}
+	}
+}
+
+// pm appends humidity and wind speed to the forcings, ahead of obs
+func pm(ds *Dataset) *Dataset {
+	for i, v := range ds.FRC {
+		ds.FRC[i] = []float64{v[0], v[1], v[2], v[3], 70., 2., v[4]}
+	}
+	return ds
+}
+
+var petModules = []struct {
+	name string
+	e    PET
+	p    []float64
+	frc  func(*Dataset) *Dataset
+}{
+	{"Makkink", &Makkink{}, []float64{1.13, -.00027}, nil},
+	{"HargreavesSamani", &HargreavesSamani{}, []float64{.0023}, nil},
+	{"Oudin", &Oudin{}, []float64{100., 5.}, nil},
+	{"Hamon", &Hamon{}, []float64{1.2}, nil},
+	{"PriestleyTaylor", &PriestleyTaylor{}, []float64{1.26, .16}, nil},
+	{"PenmanMonteith", &PenmanMonteith{}, []float64{1., .16}, pm},
+}
+
+func TestPETTimestep(t *testing.T) {
+	for _, c := range petModules {
+		t.Run(c.name, func(t *testing.T) {
+			var tot [2]float64
+			for i, ts := range []float64{secPerDay, 3600.} {
+				ds := climate(365, ts)
+				if c.frc != nil {
+					ds = c.frc(ds)
+				}
+				if err := c.e.New(ds, c.p...); err != nil {
+					t.Fatal(err)
+				}
+				for k, v := range ds.FRC {
+					tot[i] += c.e.Update(v, ds.DOY[k])
+				}
+			}
+			if tot[0] == 0. || math.Abs(tot[1]-tot[0]) > 1e-9*math.Abs(tot[0]) {
+				t.Errorf("annual PET: daily %.6f, hourly %.6f", tot[0], tot[1])
+			}
+		})
+	}
+}
+
+func TestPETParameterError(t *testing.T) {
+	ds := pm(climate(10, secPerDay))
+	for _, c := range petModules {
+		var pe *ParameterError
+		if err := c.e.New(ds, c.p[:len(c.p)-1]...); !errors.As(err, &pe) {
+			t.Errorf("%s: missing parameter returned %v, want a ParameterError", c.name, err)
+		}
+		p := append([]float64{}, c.p...)
+		p[0] = -1.
+		if err := c.e.New(ds, p...); !errors.As(err, &pe) {
+			t.Errorf("%s: %v returned %v, want a ParameterError", c.name, p, err)
+		}
+	}
+}
+
+func TestLatitudeZone(t *testing.T) {
+	ds := climate(10, secPerDay)
+	ds.UTMZone = 0
+	if _, err := ds.Latitude(); err == nil {
+		t.Error("expected an error for an unknown UTM zone")
+	}
+}
+
+func TestPenmanMonteithForcings(t *testing.T) {
+	if err := (&PenmanMonteith{}).New(climate(10, secPerDay), 1., .16); err == nil {
+		t.Error("expected an error for forcings without humidity and wind speed")
+	}
+}
@@ -7,10 +7,10 @@ import (
 	"github.com/maseology/mmio"
 )
 
-func SumHydrograph(o, s, g []float64) {
+func SumHydrograph(ds *Dataset, o, s, g []float64) {
 	// C:/Users/mason/OneDrive/R/dygraph/obssim_csv_viewer.R
-	idt, io, is, ig := make([]interface{}, Ndt), make([]interface{}, Ndt), make([]interface{}, Ndt), make([]interface{}, Ndt)
-	for i, t := range DT {
+	idt, io, is, ig := make([]interface{}, ds.Ndt), make([]interface{}, ds.Ndt), make([]interface{}, ds.Ndt), make([]interface{}, ds.Ndt)
+	for i, t := range ds.DT {
 		idt[i] = t
 		io[i] = o[i]
 		is[i] = s[i]
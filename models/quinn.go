@@ -10,31 +10,58 @@ import (
 type Quinn struct {
 	intc, imp, sz, grav                  manabe
 	fimp, n, fc, zr, ksat, f, alpha, Zwt float64
+	g                                    float64 // recharge of the last update
 }
 
 // New Quinn constructor
 // [intercepCap, impStoCap, gwCap, fImp, ksat, rootZoneDepth, porosity, fieldCap, f, alpha, zwt]
-func (m *Quinn) New(p ...float64) {
+func (m *Quinn) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("Quinn", p, len(m.Parameters())); err != nil {
+		return err
+	}
 	if fracCheck(p[3]) || p[7] > p[6] || p[4] < 0. {
-		panic("Quinn model input error")
+		return &ParameterError{"Quinn", "fImp must be [0,1], fieldCap <= porosity, ksat >= 0", p}
+	}
+	if err := m.intc.new(p[0], 1., 0.); err != nil {
+		return err
+	}
+	if err := m.imp.new(p[1], 1., 0.); err != nil {
+		return err
 	}
-	m.intc.new(p[0], 1., 0.)
-	m.imp.new(p[1], 1., 0.)
 	m.fimp = p[3]
 	m.zr = p[5]
-	m.ksat = p[4]
+	m.ksat = p[4] * ds.tsec() // [m/s] to [m/ts]
 	m.n = p[6]
 	m.fc = p[7]
-	m.sz.new(p[5]*(p[6]-p[7]), 1.-p[3], 0.)
-	m.grav.new(p[2], 1.-p[3], 0.)
+	if err := m.sz.new(p[5]*(p[6]-p[7]), 1.-p[3], 0.); err != nil {
+		return err
+	}
+	if err := m.grav.new(p[2], 1.-p[3], 0.); err != nil {
+		return err
+	}
 	m.f = p[8]
 	m.alpha = p[9]
 	m.Zwt = p[10] // setting as long-term average depth to watertable
+	return nil
 }
 
-// Update state for daily inputs
+// Update state
 func (m *Quinn) Update(p, ep float64) (float64, float64, float64) {
-	var q float64
+	a, qimp, qsat, g := m.update(p, ep)
+	return a, qimp + qsat, g
+}
+
+// UpdateFluxes updates state, returning impervious runoff (qimp) and saturation excess runoff (qsat)
+func (m *Quinn) UpdateFluxes(p, ep float64) Fluxes {
+	a, qimp, qsat, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qimp + qsat, Recharge: g,
+		Q: map[string]float64{"qimp": qimp, "qsat": qsat},
+		S: map[string]float64{"intc": m.intc.sto, "imp": m.imp.sto, "sz": m.sz.sto, "grav": m.grav.sto},
+	}
+}
+
+func (m *Quinn) update(p, ep float64) (a, qimp, qsat, g float64) {
 	pn, ae := p, ep
 	// interception
 	if m.intc.cap > 0. {
@@ -44,14 +71,14 @@ func (m *Quinn) Update(p, ep float64) (float64, float64, float64) {
 	}
 	// impervious area
 	a2, q2, _ := m.imp.update(pn, ae, 0.0)
-	q += q2 * m.fimp
+	qimp = q2 * m.fimp
 	ae -= a2 * m.fimp
 	// pervious area (root zone and gravity reservoir); no Hortonian mechanism
 	etsz := ae * (1. - m.sz.storageFraction()) // soilzone manabe already accounts for impervious coverage
 	a3, q3, g3 := m.sz.update(pn*(1.-m.fimp), etsz, m.ksat)
 	ae -= a3
 	_, q4, _ := m.grav.update(q3+g3, 0.0, 0.0) // excess moved to gravity storage
-	q += q4 * (1. - m.fimp)                    // add saturation excess runoff
+	qsat = q4 * (1. - m.fimp)                  // saturation excess runoff
 
 	gx := m.Zwt * (m.n - m.fc)
 	if gx-m.grav.sto < m.grav.cap { // ET from gravity reservoir when nearly saturated
@@ -65,9 +92,15 @@ func (m *Quinn) Update(p, ep float64) (float64, float64, float64) {
 	}
 
 	// totals
-	_, _, g := m.grav.update(0.0, 0.0, math.Min(m.grav.sto, m.alpha*m.ksat*math.Exp(-m.f*m.Zwt))) // recharge [L/TS]; setting pf = 0 and alpha sets qv = kv
-	a := ep - ae                                                                                  // returns AET
-	return a, q, g
+	_, _, g = m.grav.update(0.0, 0.0, math.Min(m.grav.sto, m.alpha*m.ksat*math.Exp(-m.f*m.Zwt))) // recharge [L/TS]; setting pf = 0 and alpha sets qv = kv
+	a = ep - ae                                                                                  // returns AET
+	m.g = g
+	return
+}
+
+// Exchange returns the recharge of the last update, lost from the model's stores
+func (m *Quinn) Exchange() float64 {
+	return -m.g
 }
 
 // Storage returns total storage
@@ -75,6 +108,40 @@ func (m *Quinn) Storage() float64 {
 	return m.intc.sto + m.imp.sto + m.sz.sto + m.grav.sto
 }
 
+// State returns the current state [intc, imp, sz, grav]
+func (m *Quinn) State() State {
+	return State{S: []float64{m.intc.sto, m.imp.sto, m.sz.sto, m.grav.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *Quinn) SetState(s State) error {
+	if err := s.check("Quinn", 4, 0); err != nil {
+		return err
+	}
+	m.intc.sto = s.S[0]
+	m.imp.sto = s.S[1]
+	m.sz.sto = s.S[2]
+	m.grav.sto = s.S[3]
+	return nil
+}
+
+// Parameters describes the Quinn model parameters
+func (m *Quinn) Parameters() []Parameter {
+	return []Parameter{
+		{"intercepCap", "m", "interception storage capacity", 0., inf, 0., .1, false},
+		{"impStoCap", "m", "impervious storage capacity", 0., inf, 0., .1, false},
+		{"gwCap", "m", "gravity reservoir capacity", 0., inf, 0., 100., false},
+		{"fImp", "-", "fraction impervious", 0., 1., 0., 1., false},
+		{"ksat", "m/s", "saturated hydraulic conductivity", 0., inf, 1e-12, 1., true},
+		{"rootZoneDepth", "mm", "root zone depth", 0., inf, 0., 1000., false},
+		{"porosity", "-", "soil porosity", 0., 1., 0., .3, false},
+		{"fieldCap", "-", "field capacity", 0., 1., 0., .1, false},
+		{"f", "1/m", "conductivity decay with depth", 0., inf, 0., 1., false},
+		{"alpha", "-", "recharge scaling factor", 0., inf, 0., 1., false},
+		{"zwt", "m", "long-term average depth to watertable", 0., inf, 0., 10., false},
+	}
+}
+
 // // SampleSpace returns a hypercube from which the optimum resides
 // func (m *Quinn) SampleSpace(u []float64) []float64 {
 // 	const sd, n, fc = 1000.0, 0.3, 0.1
@@ -0,0 +1,179 @@
+package rainrun
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Entry is a registered model: its constructor, parameter transform and dimension
+type Entry struct {
+	New    func() Model                // returns an empty Lumper or Climater, to be built with Model.New
+	Sample func(u []float64) []float64 // maps a sample from the unit hypercube onto model parameters
+	Ndim   int                         // number of sample dimensions
+}
+
+var registry = make(map[string]Entry)
+
+var (
+	snows = map[string]func() Snow{
+		"CCF":       func() Snow { return &CCF{} },
+		"CemaNeige": func() Snow { return &CemaNeige{} },
+		"DegreeDay": func() Snow { return &DegreeDay{} },
+		"Snow17":    func() Snow { return &Snow17{} },
+	}
+	pets = map[string]func() PET{
+		"HargreavesSamani": func() PET { return &HargreavesSamani{} },
+		"Hamon":            func() PET { return &Hamon{} },
+		"Makkink":          func() PET { return &Makkink{} },
+		"Oudin":            func() PET { return &Oudin{} },
+		"PenmanMonteith":   func() PET { return &PenmanMonteith{} },
+		"PriestleyTaylor":  func() PET { return &PriestleyTaylor{} },
+	}
+	uhs = map[string]func() UnitHydrograph{
+		"Clark":      func() UnitHydrograph { return UHClark{} },
+		"GR1":        func() UnitHydrograph { return UHGR1{} },
+		"GR2":        func() UnitHydrograph { return UHGR2{} },
+		"Gamma":      func() UnitHydrograph { return UHGamma{} },
+		"Nash":       func() UnitHydrograph { return UHNash{} },
+		"SCS":        func() UnitHydrograph { return UHSCS{} },
+		"Triangular": func() UnitHydrograph { return UHTriangular{} },
+	}
+)
+
+// Register makes a model available by name. The built-in models are
+// registered by package sample, where their parameter transforms reside.
+// Register panics if called twice with the same name.
+func Register(name string, e Entry) {
+	if _, ok := registry[name]; ok {
+		panic("rainrun: Register called twice for model " + name)
+	}
+	registry[name] = e
+}
+
+// RegisterSnow makes a snow module available by name to front-ends.
+// RegisterSnow panics if called twice with the same name.
+func RegisterSnow(name string, fnew func() Snow) {
+	if _, ok := snows[name]; ok {
+		panic("rainrun: RegisterSnow called twice for module " + name)
+	}
+	snows[name] = fnew
+}
+
+// RegisterPET makes a PET module available by name to front-ends.
+// RegisterPET panics if called twice with the same name.
+func RegisterPET(name string, fnew func() PET) {
+	if _, ok := pets[name]; ok {
+		panic("rainrun: RegisterPET called twice for module " + name)
+	}
+	pets[name] = fnew
+}
+
+// RegisterUH makes a unit hydrograph available by name to routed models.
+// RegisterUH panics if called twice with the same name.
+func RegisterUH(name string, fnew func() UnitHydrograph) {
+	if _, ok := uhs[name]; ok {
+		panic("rainrun: RegisterUH called twice for unit hydrograph " + name)
+	}
+	uhs[name] = fnew
+}
+
+// Lookup returns the registered model of the given name. Names of the form
+// "Lumper+Snow+PET" (e.g., "Quinn+CCF+Makkink") return the registered Lumper
+// paired with snow and PET front-ends, sampled over the concatenated parameters.
+// Names of the form "Lumper/UH" (e.g., "SIXPAR/Nash") return the registered Lumper
+// with its runoff routed through a unit hydrograph, and may be paired with front-ends
+// in turn (e.g., "SIXPAR/Nash+CCF+Makkink").
+func Lookup(name string) (Entry, bool) {
+	if e, ok := registry[name]; ok {
+		return e, ok
+	}
+	s := strings.Split(name, "+")
+	if len(s) == 1 {
+		return lookupRouted(name)
+	}
+	if len(s) != 3 {
+		return Entry{}, false
+	}
+	e, ok := Lookup(s[0])
+	if !ok {
+		return Entry{}, false
+	}
+	if _, ok := e.New().(Lumper); !ok {
+		return Entry{}, false
+	}
+	fsn, ok := snows[s[1]]
+	if !ok {
+		return Entry{}, false
+	}
+	fep, ok := pets[s[2]]
+	if !ok {
+		return Entry{}, false
+	}
+	psn, pep := fsn().Parameters(), fep().Parameters()
+	return Entry{
+		New: func() Model { return NewFrontEnd(e.New().(Lumper), fsn(), fep()) },
+		Sample: func(u []float64) []float64 {
+			p := e.Sample(u[:e.Ndim])
+			for i, par := range append(psn, pep...) {
+				p = append(p, par.Sample(u[e.Ndim+i]))
+			}
+			return p
+		},
+		Ndim: e.Ndim + len(psn) + len(pep),
+	}, true
+}
+
+// lookupRouted returns the registered Lumper of a name "Lumper/UH", routed through the unit hydrograph
+func lookupRouted(name string) (Entry, bool) {
+	s := strings.Split(name, "/")
+	if len(s) != 2 {
+		return Entry{}, false
+	}
+	e, ok := registry[s[0]]
+	if !ok {
+		return Entry{}, false
+	}
+	if _, ok := e.New().(Lumper); !ok {
+		return Entry{}, false
+	}
+	fuh, ok := uhs[s[1]]
+	if !ok {
+		return Entry{}, false
+	}
+	puh := fuh().Parameters()
+	return Entry{
+		New: func() Model { return NewRouted(e.New().(Lumper), fuh()) },
+		Sample: func(u []float64) []float64 {
+			p := e.Sample(u[:e.Ndim])
+			for i, par := range puh {
+				p = append(p, par.Sample(u[e.Ndim+i]))
+			}
+			return p
+		},
+		Ndim: e.Ndim + len(puh),
+	}, true
+}
+
+// Registered returns the sorted names of all registered models
+func Registered() []string {
+	s := make([]string, 0, len(registry))
+	for k := range registry {
+		s = append(s, k)
+	}
+	sort.Strings(s)
+	return s
+}
+
+// NewModel returns a registered model built with parameters p
+func NewModel(name string, ds *Dataset, p ...float64) (Model, error) {
+	e, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown model %s", name)
+	}
+	m := e.New()
+	if err := m.New(ds, p...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
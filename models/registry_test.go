@@ -0,0 +1,70 @@
+package rainrun
+
+import (
+	"sort"
+	"testing"
+)
+
+func init() {
+	Register("test.GR4J", Entry{
+		New:    func() Model { return &GR4J{} },
+		Sample: func(u []float64) []float64 { return mid((&GR4J{}).Parameters()) },
+		Ndim:   4,
+	})
+}
+
+func TestLookup(t *testing.T) {
+	u := make([]float64, 20)
+	for _, c := range []struct {
+		name string
+		ok   bool
+		ndim int
+	}{
+		{"test.GR4J", true, 4},
+		{"test.GR4J+CCF+Makkink", true, 11},
+		{"test.GR4J+DegreeDay+Hamon", true, 7},
+		{"test.GR4J/Nash", true, 6},
+		{"test.GR4J/Nash+Snow17+PenmanMonteith", true, 17},
+		{"test.GR4J+CCF", false, 0},
+		{"test.GR4J+CCF+unknown", false, 0},
+		{"test.GR4J/unknown", false, 0},
+		{"unknown", false, 0},
+	} {
+		e, ok := Lookup(c.name)
+		if ok != c.ok {
+			t.Errorf("%s: found %v, want %v", c.name, ok, c.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if e.Ndim != c.ndim || len(e.Sample(u[:e.Ndim])) != c.ndim {
+			t.Errorf("%s: %d dimensions, sampled %d, want %d", c.name, e.Ndim, len(e.Sample(u[:e.Ndim])), c.ndim)
+		}
+		if d, ok := e.New().(Describer); !ok || len(d.Parameters()) != c.ndim {
+			t.Errorf("%s: model does not describe its %d parameters", c.name, c.ndim)
+		}
+	}
+}
+
+func TestNewModel(t *testing.T) {
+	ds := synthetic(10, secPerDay)
+	if _, err := NewModel("test.GR4J/Nash", ds, append(mid((&GR4J{}).Parameters()), 2., 1.)...); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewModel("unknown", ds); err == nil {
+		t.Error("expected an error for an unknown model")
+	}
+	if r := Registered(); !sort.StringsAreSorted(r) || sort.SearchStrings(r, "test.GR4J") == len(r) {
+		t.Errorf("Registered() = %v", r)
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register did not panic on a duplicate name")
+		}
+	}()
+	Register("test.GR4J", Entry{})
+}
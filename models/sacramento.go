@@ -0,0 +1,310 @@
+package rainrun
+
+import "math"
+
+// Sacramento soil moisture accounting model
+// Burnash, R.J.C., R.L. Ferral, R.A. McGuire, 1973. A generalized streamflow simulation system: conceptual modeling for digital computers. US Department of Commerce, National Weather Service and State of California, Department of Water Resources. 204 pp.
+// Burnash, R.J.C., 1995. The NWS river forecast system - catchment modeling. In: Singh, V.P. (ed.), Computer Models of Watershed Hydrology. pp. 311-366.
+// following the NWSRFS fland1 routine; storages held as depths over the pervious (and additional impervious) areas
+type Sacramento struct {
+	uztwc, uzfwc, lztwc, lzfsc, lzfpc, adimc                float64 // upper/lower zone tension and free water contents; additional impervious area content
+	uztwm, uzfwm, lztwm, lzfsm, lzfpm                       float64 // capacities
+	uzk, lzsk, lzpk, pctim, adimp, riva, zperc, rexp, pfree float64
+	rserv, side, ts                                         float64
+	gl                                                      float64 // deep loss of the last update
+}
+
+// New Sacramento constructor
+// [uztwm, uzfwm, uzk, pctim, adimp, riva, zperc, rexp, lztwm, lzfsm, lzfpm, lzsk, lzpk, pfree, rserv, side]
+func (m *Sacramento) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("Sacramento", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if p[0] <= 0. || p[1] <= 0. || p[8] <= 0. || p[9] <= 0. || p[10] <= 0. {
+		return &ParameterError{"Sacramento", "storage capacities must be > 0", p}
+	}
+	if fracCheck(p[3]) || fracCheck(p[4]) || p[3]+p[4] > 1. || fracCheck(p[5]) || fracCheck(p[13]) || fracCheck(p[14]) {
+		return &ParameterError{"Sacramento", "pctim, adimp, riva, pfree and rserv must be [0,1], pctim+adimp <= 1", p}
+	}
+	if p[2] < 0. || p[11] < 0. || p[12] < 0. || p[6] < 0. || p[7] < 0. || p[15] < 0. {
+		return &ParameterError{"Sacramento", "uzk, lzsk, lzpk, zperc, rexp and side must be >= 0", p}
+	}
+	m.uztwm, m.uzfwm, m.uzk = p[0], p[1], p[2]
+	m.pctim, m.adimp, m.riva = p[3], p[4], p[5]
+	m.zperc, m.rexp = p[6], p[7]
+	m.lztwm, m.lzfsm, m.lzfpm = p[8], p[9], p[10]
+	m.lzsk, m.lzpk = p[11], p[12]
+	m.pfree, m.rserv, m.side = p[13], p[14], p[15]
+	m.ts = ds.tsec()
+	m.uztwc, m.uzfwc, m.lztwc, m.lzfsc, m.lzfpc, m.adimc = 0., 0., 0., 0., 0., 0.
+	return nil
+}
+
+// Update state
+func (m *Sacramento) Update(p, ep float64) (float64, float64, float64) {
+	a, roimp, sdro, ssur, sif, bfc, g := m.update(p, ep)
+	return a, roimp + sdro + ssur + sif + bfc, g
+}
+
+// UpdateFluxes updates state, returning impervious (roimp) and direct (sdro) runoff, surface runoff (ssur), interflow (sif) and channel baseflow (bfc), each less its share of riparian evaporation
+func (m *Sacramento) UpdateFluxes(p, ep float64) Fluxes {
+	a, roimp, sdro, ssur, sif, bfc, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: roimp + sdro + ssur + sif + bfc, Recharge: g,
+		Q: map[string]float64{"roimp": roimp, "sdro": sdro, "ssur": ssur, "sif": sif, "bfc": bfc},
+		S: map[string]float64{"uztwc": m.uztwc, "uzfwc": m.uzfwc, "lztwc": m.lztwc, "lzfsc": m.lzfsc, "lzfpc": m.lzfpc, "adimc": m.adimc},
+	}
+}
+
+func (m *Sacramento) update(pxv, edmnd float64) (tet, roimp, sdro, ssur, sif, bfc, g float64) {
+	// evapotranspiration from the upper zone
+	e1 := edmnd * m.uztwc / m.uztwm
+	red := edmnd - e1 // residual evap demand
+	m.uztwc -= e1
+	var e2 float64
+	if m.uztwc < 0. {
+		e1 += m.uztwc
+		m.uztwc = 0.
+		red = edmnd - e1
+		if m.uzfwc < red {
+			e2 = m.uzfwc
+			m.uzfwc = 0.
+			red -= e2
+		} else {
+			e2 = red
+			m.uzfwc -= e2
+			red = 0.
+		}
+	}
+	if m.uztwc/m.uztwm < m.uzfwc/m.uzfwm { // upper zone free water ratio exceeds tension water ratio, transfer free water to tension
+		uzrat := (m.uztwc + m.uzfwc) / (m.uztwm + m.uzfwm)
+		m.uztwc = m.uztwm * uzrat
+		m.uzfwc = m.uzfwm * uzrat
+	}
+	if m.uztwc < 1e-8 {
+		m.uztwc = 0.
+	}
+	if m.uzfwc < 1e-8 {
+		m.uzfwc = 0.
+	}
+
+	// evapotranspiration from the lower zone tension water
+	e3 := red * m.lztwc / (m.uztwm + m.lztwm)
+	m.lztwc -= e3
+	if m.lztwc < 0. {
+		e3 += m.lztwc
+		m.lztwc = 0.
+	}
+	ratlzt := m.lztwc / m.lztwm
+	saved := m.rserv * (m.lzfpm + m.lzfsm)
+	ratlz := (m.lztwc + m.lzfpc + m.lzfsc - saved) / (m.lztwm + m.lzfpm + m.lzfsm - saved)
+	if ratlzt < ratlz { // resupply lower zone tension water from free water
+		del := (ratlz - ratlzt) * m.lztwm
+		m.lztwc += del
+		m.lzfsc -= del
+		if m.lzfsc < 0. {
+			m.lzfpc += m.lzfsc
+			m.lzfsc = 0.
+		}
+	}
+	if m.lztwc < 1e-8 {
+		m.lztwc = 0.
+	}
+
+	// evapotranspiration from the additional impervious area
+	e5 := e1 + (red+e2)*(m.adimc-e1-m.uztwc)/(m.uztwm+m.lztwm)
+	m.adimc -= e5
+	if m.adimc < 0. {
+		e5 += m.adimc
+		m.adimc = 0.
+	}
+	e5 *= m.adimp
+
+	// infiltration to upper zone tension water
+	twx := pxv + m.uztwc - m.uztwm // time interval available moisture in excess of uztw requirements
+	if twx < 0. {
+		m.uztwc += pxv
+		twx = 0.
+	} else {
+		m.uztwc = m.uztwm
+	}
+	m.adimc += pxv - twx
+	roimp = pxv * m.pctim // runoff from the impervious area
+
+	// incremental solution of percolation, interflow, baseflow and surface runoff
+	parea := 1. - m.adimp - m.pctim
+	ninc := int(math.Floor(1. + 200.*(m.uzfwc+twx))) // number of increments, no greater than 5 mm of water each
+	dinc := m.ts / float64(ninc)                     // length of increment [s]
+	pinc := twx / float64(ninc)
+	duz, dlzp, dlzs := recession(m.uzk, dinc), recession(m.lzpk, dinc), recession(m.lzsk, dinc)
+	var sbf, spbf float64
+	for i := 0; i < ninc; i++ {
+		var adsur float64
+		ratio := math.Max((m.adimc-m.uztwc)/m.lztwm, 0.)
+		addro := pinc * ratio * ratio // direct runoff from the additional impervious area
+
+		// baseflow from free water storages
+		bf := m.lzfpc * dlzp
+		m.lzfpc -= bf
+		if m.lzfpc <= 1e-7 {
+			bf += m.lzfpc
+			m.lzfpc = 0.
+		}
+		sbf += bf
+		spbf += bf
+		bf = m.lzfsc * dlzs
+		m.lzfsc -= bf
+		if m.lzfsc <= 1e-7 {
+			bf += m.lzfsc
+			m.lzfsc = 0.
+		}
+		sbf += bf
+
+		if pinc+m.uzfwc > 1e-5 {
+			// percolation, as a function of the lower zone deficiency
+			percm := m.lzfpm*dlzp + m.lzfsm*dlzs
+			perc := percm * m.uzfwc / m.uzfwm
+			defr := 1. - (m.lztwc+m.lzfpc+m.lzfsc)/(m.lztwm+m.lzfpm+m.lzfsm)
+			perc *= 1. + m.zperc*math.Pow(math.Max(defr, 0.), m.rexp)
+			if perc > m.uzfwc {
+				perc = m.uzfwc
+			}
+			m.uzfwc -= perc
+			if check := m.lztwc + m.lzfpc + m.lzfsc + perc - m.lztwm - m.lzfpm - m.lzfsm; check > 0. {
+				perc -= check
+				m.uzfwc += check
+			}
+
+			// interflow
+			del := m.uzfwc * duz
+			sif += del
+			m.uzfwc -= del
+
+			// distribute percolated water to the lower zones
+			perct := perc * (1. - m.pfree)
+			var percf float64
+			if perct+m.lztwc > m.lztwm {
+				percf = perct + m.lztwc - m.lztwm
+				m.lztwc = m.lztwm
+			} else {
+				m.lztwc += perct
+			}
+			percf += perc * m.pfree
+			if percf > 0. {
+				hpl := m.lzfpm / (m.lzfpm + m.lzfsm)
+				ratlp, ratls := m.lzfpc/m.lzfpm, m.lzfsc/m.lzfsm
+				fracp := 1.
+				if d := (1. - ratlp) + (1. - ratls); d > 0. {
+					fracp = math.Min(hpl*2.*(1.-ratlp)/d, 1.)
+				}
+				percp := percf * fracp
+				percs := percf - percp
+				m.lzfsc += percs
+				if m.lzfsc > m.lzfsm {
+					percs -= m.lzfsc - m.lzfsm
+					m.lzfsc = m.lzfsm
+				}
+				m.lzfpc += percf - percs
+				if m.lzfpc > m.lzfpm {
+					m.lztwc += m.lzfpc - m.lzfpm
+					m.lzfpc = m.lzfpm
+				}
+			}
+
+			// distribute excess water to upper zone free water, surface runoff otherwise
+			if pinc > 0. {
+				if pinc+m.uzfwc > m.uzfwm {
+					sur := pinc + m.uzfwc - m.uzfwm
+					m.uzfwc = m.uzfwm
+					ssur += sur * parea
+					adsur = sur * (1. - addro/pinc) // surface runoff from the portion of the additional impervious area not producing direct runoff
+					ssur += adsur * m.adimp
+				} else {
+					m.uzfwc += pinc
+				}
+			}
+		} else {
+			m.uzfwc += pinc
+		}
+
+		// additional impervious area storage
+		m.adimc += pinc - addro - adsur
+		if m.adimc > m.uztwm+m.lztwm {
+			addro += m.adimc - (m.uztwm + m.lztwm)
+			m.adimc = m.uztwm + m.lztwm
+		}
+		sdro += addro * m.adimp
+		if m.adimc < 1e-8 {
+			m.adimc = 0.
+		}
+	}
+
+	// totals over the pervious area
+	sif *= parea
+	tbf := sbf * parea
+	bfc = tbf / (1. + m.side) // baseflow reaching the channel; the remainder is lost to deep groundwater
+	g = tbf - bfc
+	m.gl = g
+	eused := (e1 + e2 + e3) * parea
+
+	// riparian evapotranspiration, taken from the total channel inflow (fland1)
+	tci := roimp + sdro + ssur + sif + bfc
+	e4 := math.Min((edmnd-eused)*m.riva, tci)
+	if tci > 0. {
+		f := 1. - e4/tci
+		roimp *= f
+		sdro *= f
+		ssur *= f
+		sif *= f
+		bfc *= f
+	}
+	tet = eused + e5 + e4
+	return
+}
+
+// Exchange returns the deep groundwater loss of the last update, reported as recharge
+func (m *Sacramento) Exchange() float64 {
+	return -m.gl
+}
+
+// Storage returns total storage over the pervious and additional impervious areas
+func (m *Sacramento) Storage() float64 {
+	parea := 1. - m.adimp - m.pctim
+	return parea*(m.uztwc+m.uzfwc+m.lztwc+m.lzfsc+m.lzfpc) + m.adimp*m.adimc
+}
+
+// State returns the current state [uztwc, uzfwc, lztwc, lzfsc, lzfpc, adimc]
+func (m *Sacramento) State() State {
+	return State{S: []float64{m.uztwc, m.uzfwc, m.lztwc, m.lzfsc, m.lzfpc, m.adimc}}
+}
+
+// SetState restores a state returned by State
+func (m *Sacramento) SetState(s State) error {
+	if err := s.check("Sacramento", 6, 0); err != nil {
+		return err
+	}
+	m.uztwc, m.uzfwc, m.lztwc, m.lzfsc, m.lzfpc, m.adimc = s.S[0], s.S[1], s.S[2], s.S[3], s.S[4], s.S[5]
+	return nil
+}
+
+// Parameters describes the Sacramento model parameters
+func (m *Sacramento) Parameters() []Parameter {
+	return []Parameter{
+		{"uztwm", "m", "upper zone tension water capacity", 0., inf, .01, .3, false},
+		{"uzfwm", "m", "upper zone free water capacity", 0., inf, .005, .15, false},
+		{"uzk", "1/s", "upper zone free water (interflow) depletion rate", 0., inf, 1.2e-6, 1.6e-5, true},
+		{"pctim", "-", "fraction of permanent impervious area", 0., 1., 0., .1, false},
+		{"adimp", "-", "maximum fraction of additional impervious area", 0., 1., 0., .4, false},
+		{"riva", "-", "fraction of riparian vegetation area", 0., 1., 0., .2, false},
+		{"zperc", "-", "maximum percolation rate coefficient", 0., inf, 1., 350., false},
+		{"rexp", "-", "percolation equation exponent", 0., inf, 1., 5., false},
+		{"lztwm", "m", "lower zone tension water capacity", 0., inf, .01, .5, false},
+		{"lzfsm", "m", "lower zone supplementary free water capacity", 0., inf, .005, .4, false},
+		{"lzfpm", "m", "lower zone primary free water capacity", 0., inf, .01, 1., false},
+		{"lzsk", "1/s", "lower zone supplementary free water depletion rate", 0., inf, 1.2e-7, 5e-6, true},
+		{"lzpk", "1/s", "lower zone primary free water depletion rate", 0., inf, 1.2e-8, 5.9e-7, true},
+		{"pfree", "-", "fraction of percolation going directly to lower zone free water", 0., 1., 0., .6, false},
+		{"rserv", "-", "fraction of lower zone free water unavailable for transpiration", 0., 1., 0., .4, false},
+		{"side", "-", "ratio of deep recharge to channel baseflow", 0., inf, 0., .5, false},
+	}
+}
@@ -0,0 +1,28 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSacramento(t *testing.T) {
+	// [uztwm, uzfwm, uzk, pctim, adimp, riva, zperc, rexp, lztwm, lzfsm, lzfpm, lzsk, lzpk, pfree, rserv, side], without drainage
+	par := func(f func(p []float64)) []float64 {
+		p := []float64{.1, .05, 0., 0., 0., 0., 0., 1., .1, .1, .2, 0., 0., 0., 0., 0.}
+		if f != nil {
+			f(p)
+		}
+		return p
+	}
+	bf := .1 * (1. - math.Exp(-1e-6*secPerDay)) // daily primary baseflow from lzfpc = .1 at lzpk = 1e-6
+	// stores [uztwc, uzfwc, lztwc, lzfsc, lzfpc, adimc]
+	checkSteps(t, func() Lumper { return &Sacramento{} }, &Dataset{Timestep: secPerDay}, []step{
+		{"upper zone evaporation", par(nil), State{S: []float64{.05}}, 0., .004, .002, 0., 0., []float64{.048}},
+		{"tension water spills to free water", par(nil), State{S: []float64{.09}}, .03, 0., 0., 0., 0., []float64{.1, .02}},
+		{"free water spills to surface runoff", par(nil), State{S: []float64{.1, .05}}, .01, 0., 0., .01, 0., []float64{.1, .05}},
+		{"impervious runoff", par(func(p []float64) { p[3] = .2 }), State{}, .01, 0., 0., .002, 0., []float64{.01}},
+		{"riparian evaporation from impervious runoff", par(func(p []float64) { p[3], p[5] = .2, .25 }), State{}, .01, .004, .001, .001, 0., []float64{.01}},
+		{"riparian evaporation limited to channel inflow", par(func(p []float64) { p[3], p[5] = .2, 1. }), State{}, .01, .004, .002, 0., 0., []float64{.01}},
+		{"baseflow split with deep loss", par(func(p []float64) { p[12], p[15] = 1e-6, .25 }), State{S: []float64{0., 0., .1, 0., .1}}, 0., 0., 0., bf / 1.25, bf * .25 / 1.25, []float64{0., 0., .1, 0., .1 - bf}},
+	})
+}
@@ -0,0 +1,68 @@
+package rainrun
+
+// CCFSacramento model
+// Sacramento soil moisture accounting with CCF snowmelt model and Makkink PET of fixed coefficients
+type CCFSacramento struct {
+	Sacramento
+	CCF
+	Makkink
+}
+
+// New CCFSacramento constructor
+// [uztwm, uzfwm, uzk, pctim, adimp, riva, zperc, rexp, lztwm, lzfsm, lzfpm, lzsk, lzpk, pfree, rserv, side]
+// [tindex, ddfc, baseT, tsf, ddf]
+func (m *CCFSacramento) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("CCFSacramento", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if err := m.Sacramento.New(ds, p[:16]...); err != nil {
+		return err
+	}
+	if err := m.CCF.New(ds, p[16:21]...); err != nil {
+		return err
+	}
+	return m.Makkink.New(ds, 1.13, -.00027)
+}
+
+// Update state for daily inputs
+func (m *CCFSacramento) Update(v []float64, doy int) (y, a, r, g float64) {
+	y, ep := m.CCF.Update(v, doy), m.Makkink.Update(v, doy)
+	a, r, g = m.Sacramento.Update(y, ep)
+	return
+}
+
+// UpdateFluxes updates state, adding snowpack yield (y), PET (ep) and snowpack water content (swe) to the Sacramento fluxes
+func (m *CCFSacramento) UpdateFluxes(v []float64, doy int) Fluxes {
+	y, ep := m.CCF.Update(v, doy), m.Makkink.Update(v, doy)
+	f := m.Sacramento.UpdateFluxes(y, ep)
+	f.Q["y"], f.Q["ep"] = y, ep
+	f.S["swe"] = m.CCF.SWE()
+	return f
+}
+
+// Storage returns the Sacramento storage plus the snowpack water content
+func (m *CCFSacramento) Storage() float64 {
+	return m.Sacramento.Storage() + m.CCF.SWE()
+}
+
+// Parameters describes the CCFSacramento model parameters
+func (m *CCFSacramento) Parameters() []Parameter {
+	return append(m.Sacramento.Parameters(), CCFParameters()...)
+}
+
+// State returns the current Sacramento state, followed by the snowpack state
+func (m *CCFSacramento) State() State {
+	return joinState(m.Sacramento.State(), m.CCF.State())
+}
+
+// SetState restores a state returned by State
+func (m *CCFSacramento) SetState(s State) error {
+	ss, sn, err := splitState(s, m.CCF.State())
+	if err != nil {
+		return err
+	}
+	if err := m.Sacramento.SetState(ss); err != nil {
+		return err
+	}
+	return m.CCF.SetState(sn)
+}
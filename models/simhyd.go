@@ -0,0 +1,97 @@
+package rainrun
+
+import "math"
+
+// SIMHYD model
+// Chiew, F.H.S., M.C. Peel, A.W. Western, 2002. Application and testing of the simple rainfall-runoff model SIMHYD. In: Singh, V.P., D.K. Frevert (eds.), Mathematical Models of Small Watershed Hydrology and Applications. pp. 335-367.
+type SIMHYD struct {
+	sms, gw              res // soil moisture and groundwater stores
+	insc, coeff, sq, sub float64
+	crak, emax           float64
+}
+
+// New SIMHYD constructor
+// [insc, coeff, sq, smsc, sub, crak, k]
+func (m *SIMHYD) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("SIMHYD", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if p[0] < 0. || p[1] < 0. || p[2] < 0. || p[3] <= 0. || fracCheck(p[4]) || fracCheck(p[5]) || p[6] < 0. {
+		return &ParameterError{"SIMHYD", "insc, coeff, sq and k must be >= 0, smsc > 0, sub and crak must be [0,1]", p}
+	}
+	ts := ds.tsec()
+	m.insc = p[0]       // interception store capacity
+	m.coeff = p[1] * ts // maximum infiltration loss [m/s]
+	m.sq = p[2]         // infiltration loss exponent
+	m.sms.new(p[3], 0.) // smsc: soil moisture store capacity
+	m.sub = p[4]        // interflow coefficient
+	m.crak = p[5]       // recharge coefficient
+	m.gw.new(math.MaxFloat64, recession(p[6], ts))
+	m.emax = .01 * ts / secPerDay // maximum soil evapotranspiration of 10 mm/d
+	m.sms.sto, m.gw.sto = 0., 0.
+	return nil
+}
+
+// Update state
+func (m *SIMHYD) Update(p, ep float64) (float64, float64, float64) {
+	a, qi, qs, qb, g := m.update(p, ep)
+	return a, qi + qs + qb, g
+}
+
+// UpdateFluxes updates state, returning infiltration excess runoff (qi), interflow and saturation excess runoff (qs) and baseflow (qb)
+func (m *SIMHYD) UpdateFluxes(p, ep float64) Fluxes {
+	a, qi, qs, qb, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qi + qs + qb, Recharge: g,
+		Q: map[string]float64{"qi": qi, "qs": qs, "qb": qb},
+		S: map[string]float64{"sms": m.sms.sto, "gw": m.gw.sto},
+	}
+}
+
+func (m *SIMHYD) update(p, ep float64) (a, qi, qs, qb, g float64) {
+	intc := math.Min(math.Min(m.insc, ep), p) // interception loss
+	inr := p - intc
+	sf := m.sms.storageFraction()
+	rmo := math.Min(m.coeff*math.Exp(-m.sq*sf), inr) // infiltration
+	qi = inr - rmo                                   // infiltration excess runoff
+	qs = m.sub * sf * rmo                            // interflow and saturation excess runoff
+	g = m.crak * sf * (rmo - qs)                     // recharge
+	et := math.Min(math.Min(m.emax*sf, ep-intc), m.sms.sto+rmo-qs-g)
+	g += m.sms.overflow(rmo - qs - g - et) // soil moisture in excess of capacity becomes recharge
+	m.gw.update(g)
+	qb = m.gw.decayExp()
+	a = intc + et
+	return
+}
+
+// Storage returns total storage
+func (m *SIMHYD) Storage() float64 {
+	return m.sms.sto + m.gw.sto
+}
+
+// State returns the current state [sms, gw]
+func (m *SIMHYD) State() State {
+	return State{S: []float64{m.sms.sto, m.gw.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *SIMHYD) SetState(s State) error {
+	if err := s.check("SIMHYD", 2, 0); err != nil {
+		return err
+	}
+	m.sms.sto, m.gw.sto = s.S[0], s.S[1]
+	return nil
+}
+
+// Parameters describes the SIMHYD model parameters
+func (m *SIMHYD) Parameters() []Parameter {
+	return []Parameter{
+		{"insc", "m", "interception store capacity", 0., inf, .0005, .005, false},
+		{"coeff", "m/s", "maximum infiltration loss", 0., inf, 5.8e-7, 4.6e-6, false},
+		{"sq", "-", "infiltration loss exponent", 0., inf, 0., 6., false},
+		{"smsc", "m", "soil moisture store capacity", 0., inf, .05, .5, false},
+		{"sub", "-", "constant of proportionality in interflow equation", 0., 1., 0., 1., false},
+		{"crak", "-", "constant of proportionality in groundwater recharge equation", 0., 1., 0., 1., false},
+		{"k", "1/s", "baseflow linear recession rate", 0., inf, 3.5e-8, 4.1e-6, true},
+	}
+}
@@ -0,0 +1,15 @@
+package rainrun
+
+import "testing"
+
+func TestSIMHYD(t *testing.T) {
+	p := []float64{.002, 1e-7, 0., .1, .5, .5, 0.} // [insc, coeff, sq, smsc, sub, crak, k]
+	rmo := 1e-7 * secPerDay                        // daily maximum infiltration at coeff = 1e-7 m/s and sq = 0
+	// stores [sms, gw]
+	checkSteps(t, func() Lumper { return &SIMHYD{} }, &Dataset{Timestep: secPerDay}, []step{
+		{"interception only", p, State{}, .001, .004, .001, 0., 0., []float64{0., 0.}},
+		{"infiltration excess on a dry store", p, State{}, .03, 0., 0., .03 - rmo, 0., nil},
+		{"interflow and recharge from a half-full store", p, State{S: []float64{.05}}, .01, 0., 0., .01 - rmo + .25*rmo, .25 * .75 * rmo, nil},
+		{"soil evaporation", p, State{S: []float64{.05}}, 0., .004, .004, 0., 0., []float64{.046}},
+	})
+}
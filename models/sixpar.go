@@ -14,29 +14,49 @@ type SIXPAR struct {
 
 // New SIXPAR constructor
 // [upCap, lowCap, upK, lowK, z, x]
-func (m *SIXPAR) New(p ...float64) {
+func (m *SIXPAR) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("SIXPAR", p, len(m.Parameters())); err != nil {
+		return err
+	}
 	// for TWOPAR, set pLM=0, variables pLK, pZ, pX, will have no impact
-	m.up.new(p[0], p[2])  // upper reservoir: fast subsurface flow (interflow)
-	m.low.new(p[1], p[3]) // update lower reservoir: slow subsurface flow (baseflow)
-	m.beta = p[1] * p[3]
+	ts := ds.tsec()
+	m.up.new(p[0], recession(p[2], ts))  // upper reservoir: fast subsurface flow (interflow)
+	m.low.new(p[1], recession(p[3], ts)) // update lower reservoir: slow subsurface flow (baseflow)
+	m.beta = p[1] * m.low.k
 	m.z = p[4]
 	m.x = p[5]
+	return nil
 }
 
-// Update state for daily inputs
+// Update state
 func (m *SIXPAR) Update(p, ep float64) (float64, float64, float64) {
+	qo, qi, qb, g := m.update(p, ep)
+	return ep, qo + qi + qb, g
+}
+
+// UpdateFluxes updates state, returning saturation excess overland runoff (qo), interflow (qi) and baseflow (qb)
+func (m *SIXPAR) UpdateFluxes(p, ep float64) Fluxes {
+	qo, qi, qb, g := m.update(p, ep)
+	return Fluxes{
+		AET: ep, Runoff: qo + qi + qb, Recharge: g,
+		Q: map[string]float64{"qo": qo, "qi": qi, "qb": qb},
+		S: map[string]float64{"up": m.up.sto, "low": m.low.sto},
+	}
+}
+
+func (m *SIXPAR) update(p, ep float64) (qo, qi, qb, g float64) {
 	pn := p - ep // net precipitation less ET
 	var lt float64
 	if m.low.cap > 0. {
 		lt = 1. - m.low.storageFraction()
 	}
 	ut := m.up.storageFraction()
-	g := math.Min(m.beta*ut*(1.+m.z*math.Pow(lt, m.x)), m.up.sto) // percolation from upper reservoir to lower reservoir (PDt=0 if pLM=0)
-	q := m.up.overflow(pn - g)                                    // add rainfall and remove percolation from upper reservoir, remainder becomes saturation excess overland runoff
-	m.low.update(g)                                               // add percolation to lower reservoir
+	g = math.Min(m.beta*ut*(1.+m.z*math.Pow(lt, m.x)), m.up.sto) // percolation from upper reservoir to lower reservoir (PDt=0 if pLM=0)
+	qo = m.up.overflow(pn - g)                                   // add rainfall and remove percolation from upper reservoir, remainder becomes saturation excess overland runoff
+	m.low.update(g)                                              // add percolation to lower reservoir
 	// add baseflow to runoff and update reservoirs
-	q += m.up.decayExp() + m.low.decayExp() // _uk * USt + _lk * LSt 'total discharge
-	return ep, q, g
+	qi, qb = m.up.decayExp(), m.low.decayExp() // _uk * USt + _lk * LSt 'total discharge
+	return
 }
 
 // Storage returns total storage
@@ -44,6 +64,33 @@ func (m *SIXPAR) Storage() float64 {
 	return m.up.sto + m.low.sto
 }
 
+// State returns the current state [up, low]
+func (m *SIXPAR) State() State {
+	return State{S: []float64{m.up.sto, m.low.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *SIXPAR) SetState(s State) error {
+	if err := s.check("SIXPAR", 2, 0); err != nil {
+		return err
+	}
+	m.up.sto = s.S[0]
+	m.low.sto = s.S[1]
+	return nil
+}
+
+// Parameters describes the SIXPAR model parameters
+func (m *SIXPAR) Parameters() []Parameter {
+	return []Parameter{
+		{"upCap", "m", "upper reservoir capacity", 0., inf, 0., 100., false},
+		{"lowCap", "m", "lower reservoir capacity", 0., inf, 0., 100., false},
+		{"upK", "1/s", "upper reservoir recession coefficient", 0., inf, 1e-9, 1e-4, true},
+		{"lowK", "1/s", "lower reservoir recession coefficient", 0., inf, 1e-9, 1e-4, true},
+		{"z", "-", "percolation equation parameter", 0., inf, 0., 1., false},
+		{"x", "-", "percolation equation exponent", 0., inf, 0., 1., false},
+	}
+}
+
 // // SampleSpace returns a hypercube from which the optimum resides
 // func (m *SIXPAR) SampleSpace(u []float64) []float64 {
 // 	upCap := mm.LinearTransform(0., 100., u[0])
@@ -0,0 +1,333 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+)
+
+// Snow : interface to snowpack modules, converting rain and snowfall into
+// atmospheric yield (rainfall + snowmelt)
+// v: [tmax, tmin, rain, snow]
+type Snow interface {
+	Describer
+	New(ds *Dataset, p ...float64) error
+	Update(v []float64, doy int) float64
+	SWE() float64
+	State() State
+	SetState(s State) error
+}
+
+// CCF cold-content snowmelt module
+// [tindex, ddfc, baseT, tsf, ddf]
+type CCF struct {
+	sp coldContent
+}
+
+// New CCF constructor; the daily temperature index and degree-day factor are scaled to the timestep
+func (s *CCF) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("CCF", s.Parameters(), p); err != nil {
+		return err
+	}
+	tindex, ddfc, baseT, tsf, ddf := p[0], p[1], p[2], p[3], p[4]
+	s.sp = newColdContent(tindex, ddf, ddfc, baseT, tsf, ds.tsec()/secPerDay)
+	return nil
+}
+
+// Update returns the snowpack yield
+func (s *CCF) Update(v []float64, doy int) float64 {
+	tx, tn, r, sf := v[0], v[1], v[2], v[3]
+	return s.sp.update(r, sf, (tx+tn)/2.)
+}
+
+// SWE returns the snowpack water content
+func (s *CCF) SWE() float64 {
+	return s.sp.swe()
+}
+
+// Parameters describes the CCF parameters
+func (s *CCF) Parameters() []Parameter {
+	return CCFParameters()
+}
+
+// State returns the current state: [swe, liquid water, cold content, antecedent temperature index]
+func (s *CCF) State() State {
+	return State{S: s.sp.state()}
+}
+
+// SetState restores a state returned by State
+func (s *CCF) SetState(st State) error {
+	if err := st.check("CCF", 4, 0); err != nil {
+		return err
+	}
+	s.sp.setState(st.S)
+	return nil
+}
+
+// DegreeDay snowmelt module
+// [ddf, tb]
+type DegreeDay struct {
+	DDF, Tb float64
+	swe, fd float64 // fd: timestep [d]
+}
+
+// New DegreeDay constructor
+func (s *DegreeDay) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("DegreeDay", s.Parameters(), p); err != nil {
+		return err
+	}
+	s.DDF, s.Tb = p[0], p[1]
+	s.swe, s.fd = 0., ds.tsec()/secPerDay
+	return nil
+}
+
+// Update returns rainfall plus snowmelt
+func (s *DegreeDay) Update(v []float64, doy int) float64 {
+	tx, tn, r, sf := v[0], v[1], v[2], v[3]
+	s.swe += sf
+	m := math.Min(s.DDF*s.fd*math.Max((tx+tn)/2.-s.Tb, 0.), s.swe)
+	s.swe -= m
+	return r + m
+}
+
+// SWE returns the snowpack water content
+func (s *DegreeDay) SWE() float64 {
+	return s.swe
+}
+
+// Parameters describes the DegreeDay parameters
+func (s *DegreeDay) Parameters() []Parameter {
+	return []Parameter{
+		{"ddf", "m/°C/d", "degree-day factor", 0., inf, .001, .008, false},
+		{"tb", "°C", "base/critical melt temperature", -inf, inf, -3., 3., false},
+	}
+}
+
+// State returns the current state [swe]
+func (s *DegreeDay) State() State {
+	return State{S: []float64{s.swe}}
+}
+
+// SetState restores a state returned by State
+func (s *DegreeDay) SetState(st State) error {
+	if err := st.check("DegreeDay", 1, 0); err != nil {
+		return err
+	}
+	s.swe = st.S[0]
+	return nil
+}
+
+// CemaNeige snow accounting routine, single layer, as coupled with GR4J in airGR
+// Valéry, A., V. Andréassian, C. Perrin, 2014. 'As simple as possible but not simpler': What is useful in a temperature-based snow-accounting routine? Part 2. Journal of Hydrology 517. pp. 1176-1187.
+// [ctg, kf]
+type CemaNeige struct {
+	CTG, Kf float64
+	g, etg  float64 // snowpack; thermal state [°C]
+	gthresh float64 // melt threshold, 90% of the mean annual solid precipitation
+	ctg, kf float64 // CTG and Kf scaled to the timestep
+}
+
+// New CemaNeige constructor
+func (s *CemaNeige) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("CemaNeige", s.Parameters(), p); err != nil {
+		return err
+	}
+	ssum := 0.
+	for _, v := range ds.FRC {
+		if len(v) < 4 {
+			return fmt.Errorf("CemaNeige: forcings [tmax, tmin, rain, snow, ..] do not carry snowfall")
+		}
+		ssum += v[3]
+	}
+	s.CTG, s.Kf = p[0], p[1]
+	fd := ds.tsec() / secPerDay
+	s.ctg, s.kf = math.Pow(p[0], fd), p[1]*fd // daily weighting compounded over the timestep
+	s.g, s.etg = 0., 0.
+	s.gthresh = func() float64 {
+		nyr := float64(ds.Ndt) * ds.tsec() / secPerDay / 365.25
+		if nyr <= 0. || ssum <= 0. {
+			return mingtzero
+		}
+		return .9 * ssum / nyr
+	}()
+	return nil
+}
+
+// Update returns rainfall plus snowmelt
+func (s *CemaNeige) Update(v []float64, doy int) float64 {
+	const (
+		tmelt    = 0.
+		minspeed = .1
+	)
+	tx, tn, r, sf := v[0], v[1], v[2], v[3]
+	tm := (tx + tn) / 2.
+	s.g += sf
+	s.etg = math.Min(s.ctg*s.etg+(1.-s.ctg)*tm, 0.) // snowpack thermal state
+	var potmelt float64
+	if s.etg == 0. && tm > tmelt {
+		potmelt = math.Min(s.g, s.kf*(tm-tmelt))
+	}
+	gratio := math.Min(s.g/s.gthresh, 1.)
+	m := ((1.-minspeed)*gratio + minspeed) * potmelt
+	s.g -= m
+	return r + m
+}
+
+// SWE returns the snowpack water content
+func (s *CemaNeige) SWE() float64 {
+	return s.g
+}
+
+// Parameters describes the CemaNeige parameters
+func (s *CemaNeige) Parameters() []Parameter {
+	return []Parameter{
+		{"ctg", "-", "snowpack thermal state weighting coefficient", 0., 1., 0., 1., false},
+		{"kf", "m/°C/d", "degree-day melt factor", 0., inf, 0., .01, false},
+	}
+}
+
+// State returns the current state [g, etg]
+func (s *CemaNeige) State() State {
+	return State{S: []float64{s.g, s.etg}}
+}
+
+// SetState restores a state returned by State
+func (s *CemaNeige) SetState(st State) error {
+	if err := st.check("CemaNeige", 2, 0); err != nil {
+		return err
+	}
+	s.g, s.etg = st.S[0], st.S[1]
+	return nil
+}
+
+// Snow17 snow accumulation and ablation model, temperature-index form with rain-on-snow energy balance
+// Anderson, E.A., 2006. Snow accumulation and ablation model – SNOW-17. NWS River Forecast System User Manual.
+// [scf, mfmax, mfmin, uadj, nmf, tipm, mbase, plwhc, daygm]
+type Snow17 struct {
+	scf, mfmax, mfmin, uadj, nmf, tipm, mbase, plwhc, daygm float64
+	pa, dt, fd                                              float64 // atmospheric pressure [mb]; timestep [h] and [d]
+	wi, wq, ati, d                                          float64 // ice; liquid water; antecedent temperature index [°C]; heat deficit [m]
+	xs                                                      float64 // snowfall correction of the last update
+}
+
+// New Snow17 constructor
+func (s *Snow17) New(ds *Dataset, p ...float64) error {
+	if err := checkBounds("Snow17", s.Parameters(), p); err != nil {
+		return err
+	}
+	if p[1] <= 0. {
+		return &ParameterError{"Snow17", "mfmax must be > 0", p}
+	}
+	s.scf, s.mfmax, s.mfmin, s.uadj, s.nmf, s.tipm, s.mbase, s.plwhc, s.daygm = p[0], p[1], p[2], p[3], p[4], p[5], p[6], p[7], p[8]
+	e := ds.Elevation() / 100.
+	s.pa = 33.86 * (29.9 - .335*e + .00022*math.Pow(math.Max(e, 0.), 2.4))
+	s.dt, s.fd = ds.tsec()/3600., ds.tsec()/secPerDay
+	s.wi, s.wq, s.ati, s.d = 0., 0., 0., 0.
+	return nil
+}
+
+// Update returns rainfall passing through or draining from the snowpack, plus ground melt
+func (s *Snow17) Update(v []float64, doy int) float64 {
+	const (
+		sbc    = 6.12e-10 // Stefan-Boltzmann constant [mm/K/h]
+		defmax = .33      // maximum heat deficit, as a fraction of ice content
+	)
+	dt := s.dt
+	rmin := .00025 * dt / 6.
+	snmin := .0015 * s.fd // new snowfall that resets the antecedent temperature index [m]
+	tx, tn, r, sf := v[0], v[1], v[2], v[3]
+	ta := (tx + tn) / 2.
+	pn := s.scf * sf
+	s.xs = pn - sf
+	if s.wi+pn <= 0. {
+		return r
+	}
+
+	// accumulation
+	tsn := math.Min(ta, 0.)
+	s.wi += pn
+	s.d += -tsn * pn / 160.
+
+	// melt
+	mf := ((s.mfmax+s.mfmin)/2. + math.Sin(2.*math.Pi*(float64(doy)-81.)/366.)*(s.mfmax-s.mfmin)/2.) * s.fd // seasonal melt factor, over the timestep
+	var m float64
+	if r > rmin { // rain-on-snow
+		tr := math.Max(ta, 0.)
+		m = sbc*dt*(math.Pow(tr+273., 4.)-math.Pow(273., 4.)) + .0125*r*1000.*tr + 8.5*s.uadj*dt/6.*((.9*esat(tr)*10.-6.11)+.00057*s.pa*tr)
+		m = math.Max(m/1000., 0.)
+	} else if ta > s.mbase {
+		m = mf*(ta-s.mbase) + .0125*r*ta
+	}
+	m = math.Min(m, s.wi)
+	s.wi -= m
+
+	// heat deficit
+	if pn > snmin {
+		s.ati = tsn
+	} else {
+		s.ati += (1. - math.Pow(1.-s.tipm, dt/6.)) * (ta - s.ati)
+	}
+	s.ati = math.Min(s.ati, 0.)
+	if m <= 0. {
+		s.d += s.nmf * mf / s.mfmax * (s.ati - tsn) // negative melt factor, scaled seasonally and to the timestep through mf
+	}
+	s.d = math.Min(math.Max(s.d, 0.), defmax*s.wi)
+
+	// liquid water and refreezing
+	s.wq += m + r
+	frz := math.Min(s.d, s.wq)
+	s.wi += frz
+	s.wq -= frz
+	s.d -= frz
+	e := math.Max(s.wq-s.plwhc*s.wi, 0.)
+	s.wq -= e
+
+	// ground melt
+	gm := math.Min(s.daygm*s.fd, s.wi)
+	s.wi -= gm
+	e += gm
+
+	if s.wi <= 0. { // pack is gone
+		e += s.wq
+		s.wi, s.wq, s.ati, s.d = 0., 0., 0., 0.
+	}
+	return e
+}
+
+// Exchange returns the water added (>0) or removed (<0) by the snowfall correction factor during the last update
+func (s *Snow17) Exchange() float64 {
+	return s.xs
+}
+
+// SWE returns the snowpack water content: ice plus liquid water
+func (s *Snow17) SWE() float64 {
+	return s.wi + s.wq
+}
+
+// Parameters describes the Snow17 parameters
+func (s *Snow17) Parameters() []Parameter {
+	return []Parameter{
+		{"scf", "-", "snowfall correction factor", 0., inf, .7, 1.4, false},
+		{"mfmax", "m/°C/d", "maximum melt factor, June 21", 0., inf, .002, .008, false},
+		{"mfmin", "m/°C/d", "minimum melt factor, December 21", 0., inf, .0005, .004, false},
+		{"uadj", "mm/mb/6h", "average wind function during rain-on-snow events", 0., inf, .02, .2, false},
+		{"nmf", "m/°C/d", "maximum negative melt factor", 0., inf, .0002, .002, false},
+		{"tipm", "-", "antecedent temperature index weighting", 0., 1., .01, 1., false},
+		{"mbase", "°C", "base temperature for non-rain melt", -inf, inf, -1., 1., false},
+		{"plwhc", "-", "liquid water holding capacity, as a fraction of ice content", 0., inf, .02, .3, false},
+		{"daygm", "m/d", "daily ground melt", 0., inf, 0., .0003, false},
+	}
+}
+
+// State returns the current state [wi, wq, ati, d]
+func (s *Snow17) State() State {
+	return State{S: []float64{s.wi, s.wq, s.ati, s.d}}
+}
+
+// SetState restores a state returned by State
+func (s *Snow17) SetState(st State) error {
+	if err := st.check("Snow17", 4, 0); err != nil {
+		return err
+	}
+	s.wi, s.wq, s.ati, s.d = st.S[0], st.S[1], st.S[2], st.S[3]
+	return nil
+}
@@ -0,0 +1,108 @@
+package rainrun
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+var snowModules = []struct {
+	name string
+	s    Snow
+	p    []float64
+}{
+	{"CCF", &CCF{}, []float64{.002, 1.1, 0., .3, .0045}},
+	{"DegreeDay", &DegreeDay{}, []float64{.003, 0.}},
+	{"CemaNeige", &CemaNeige{}, []float64{.5, .004}},
+	{"Snow17", &Snow17{}, []float64{1.1, .005, .002, .05, .0005, .1, 0., .05, .0001}},
+}
+
+func TestSnowClosure(t *testing.T) {
+	for _, ts := range []float64{secPerDay, 3600.} {
+		ds := climate(730, ts)
+		for _, c := range snowModules {
+			t.Run(fmt.Sprintf("%s/%.0fs", c.name, ts), func(t *testing.T) {
+				if err := c.s.New(ds, c.p...); err != nil {
+					t.Fatal(err)
+				}
+				for i, v := range ds.FRC {
+					s0 := c.s.SWE()
+					y := c.s.Update(v, ds.DOY[i])
+					if e := v[2] + v[3] + Exchange(c.s) - y - (c.s.SWE() - s0); math.Abs(e) > 1e-12 {
+						t.Fatalf("mass balance error at step %d: residual %.3e", i+1, e)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestSnowTimestep compares end-of-day snowpacks simulated at daily and hourly steps
+func TestSnowTimestep(t *testing.T) {
+	for _, c := range snowModules {
+		t.Run(c.name, func(t *testing.T) {
+			var swe [2][]float64
+			for k, ts := range []float64{secPerDay, 3600.} {
+				ds := climate(365, ts)
+				if err := c.s.New(ds, c.p...); err != nil {
+					t.Fatal(err)
+				}
+				nd := int(secPerDay / ts)
+				for i, v := range ds.FRC {
+					c.s.Update(v, ds.DOY[i])
+					if (i+1)%nd == 0 {
+						swe[k] = append(swe[k], c.s.SWE())
+					}
+				}
+			}
+			smx, dmx := 0., 0.
+			for i := range swe[0] {
+				smx = math.Max(smx, swe[0][i])
+				dmx = math.Max(dmx, math.Abs(swe[1][i]-swe[0][i]))
+			}
+			if smx == 0. || dmx > .1*smx {
+				t.Errorf("peak swe %.4f, maximum daily-hourly difference %.4f", smx, dmx)
+			}
+		})
+	}
+}
+
+func TestSnowParameterError(t *testing.T) {
+	ds := climate(10, secPerDay)
+	for _, c := range snowModules {
+		var pe *ParameterError
+		if err := c.s.New(ds, c.p[:len(c.p)-1]...); !errors.As(err, &pe) {
+			t.Errorf("%s: missing parameter returned %v, want a ParameterError", c.name, err)
+		}
+		p := append([]float64{}, c.p...)
+		p[0] = -1.
+		if err := c.s.New(ds, p...); !errors.As(err, &pe) {
+			t.Errorf("%s: %v returned %v, want a ParameterError", c.name, p, err)
+		}
+	}
+	ds.FRC[3] = ds.FRC[3][:3]
+	if err := (&CemaNeige{}).New(ds, .5, .004); err == nil {
+		t.Error("CemaNeige: expected an error for forcings without snowfall")
+	}
+}
+
+func TestDegreeDayMelt(t *testing.T) {
+	for _, ts := range []float64{secPerDay, 3600.} {
+		ds := &Dataset{Timestep: ts}
+		s := &DegreeDay{}
+		if err := s.New(ds, .003, 0.); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetState(State{S: []float64{.05}}); err != nil {
+			t.Fatal(err)
+		}
+		y := 0.
+		for i := 0; i < int(secPerDay/ts); i++ {
+			y += s.Update([]float64{8., 2., 0., 0.}, 1)
+		}
+		if math.Abs(y-.015) > 1e-12 || math.Abs(s.SWE()-.035) > 1e-12 { // 3 mm/°C/d × 5°C
+			t.Errorf("ts=%.0f: daily melt %g, swe %g; want 0.015, 0.035", ts, y, s.SWE())
+		}
+	}
+}
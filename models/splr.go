@@ -10,26 +10,47 @@ type SPLR struct {
 
 // New SPLR constructor
 // [r12, r23, k1, k2, k3]
-func (m *SPLR) New(p ...float64) {
+func (m *SPLR) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("SPLR", p, len(m.Parameters())); err != nil {
+		return err
+	}
 	m.r12 = p[0]
 	m.r23 = p[1]
-	m.k1 = p[2]
-	m.k2 = p[3]
-	m.k3 = p[4]
+	ts := ds.tsec()
+	m.k1 = recession(p[2], ts)
+	m.k2 = recession(p[3], ts)
+	m.k3 = recession(p[4], ts)
+	return nil
 }
 
-// Update state for daily inputs, returns excess
+// Update state, returns excess
 func (m *SPLR) Update(p, ep float64) (float64, float64, float64) {
+	aet, q1, q2, q3, g := m.update(p, ep)
+	return aet, q1 + q2 + q3, g
+}
+
+// UpdateFluxes updates state, returning the discharge of each reservoir (q1, q2, q3)
+func (m *SPLR) UpdateFluxes(p, ep float64) Fluxes {
+	aet, q1, q2, q3, g := m.update(p, ep)
+	return Fluxes{
+		AET: aet, Runoff: q1 + q2 + q3, Recharge: g,
+		Q: map[string]float64{"q1": q1, "q2": q2, "q3": q3},
+		S: map[string]float64{"s1": m.s1, "s2": m.s2, "s3": m.s3},
+	}
+}
+
+func (m *SPLR) update(p, ep float64) (aet, q1, q2, q3, g float64) {
 	pn, sv := p-ep, m.Storage()
 	u(&m.s1, m.r12*pn)
 	u(&m.s2, (1.-m.r12)*m.r23*pn)
 	u(&m.s3, (1.-m.r12)*(1.-m.r23)*pn)
-	aet := sv - m.Storage() + pn
-	g := pn * (m.r12 + (1.-m.r12)*m.r23 + (1.-m.r12)*(1.-m.r23))
+	aet = sv - m.Storage() + pn
+	g = pn * (m.r12 + (1.-m.r12)*m.r23 + (1.-m.r12)*(1.-m.r23))
 	if g < 0. {
 		g = 0.
 	}
-	return aet, q(&m.s1, m.k1) + q(&m.s2, m.k2) + q(&m.s3, m.k3), g
+	q1, q2, q3 = q(&m.s1, m.k1), q(&m.s2, m.k2), q(&m.s3, m.k3)
+	return
 }
 
 func u(s *float64, p float64) {
@@ -50,6 +71,33 @@ func (m *SPLR) Storage() float64 {
 	return m.s1 + m.s2 + m.s3
 }
 
+// State returns the current state [s1, s2, s3]
+func (m *SPLR) State() State {
+	return State{S: []float64{m.s1, m.s2, m.s3}}
+}
+
+// SetState restores a state returned by State
+func (m *SPLR) SetState(s State) error {
+	if err := s.check("SPLR", 3, 0); err != nil {
+		return err
+	}
+	m.s1 = s.S[0]
+	m.s2 = s.S[1]
+	m.s3 = s.S[2]
+	return nil
+}
+
+// Parameters describes the SPLR model parameters
+func (m *SPLR) Parameters() []Parameter {
+	return []Parameter{
+		{"r12", "-", "fraction of net precipitation to reservoir 1", 0., 1., 0., 1., false},
+		{"r23", "-", "fraction of remaining net precipitation to reservoir 2", 0., 1., 0., 1., false},
+		{"k1", "1/s", "reservoir 1 recession coefficient", 0., inf, 1e-9, 1e-4, true},
+		{"k2", "1/s", "reservoir 2 recession coefficient", 0., inf, 1e-9, 1e-4, true},
+		{"k3", "1/s", "reservoir 3 recession coefficient", 0., inf, 1e-9, 1e-4, true},
+	}
+}
+
 // // SampleSpace returns a hypercube from which the optimum resides
 // func (m *SPLR) SampleSpace(u []float64) []float64 {
 // 	r12 := mm.LinearTransform(0., 1., u[0])
@@ -0,0 +1,74 @@
+package rainrun
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/maseology/mmio"
+)
+
+// State is a snapshot of a model's state variables, used to pause, save and warm-start simulations
+type State struct {
+	S []float64   // store levels, ordered as given by the model
+	V [][]float64 // convolution vectors (unit hydrographs, transfer functions)
+}
+
+// WriteState saves a model state to file, as .json or .gob
+func WriteState(fp string, s State) error {
+	f, err := os.Create(fp)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	switch mmio.GetExtension(fp) {
+	case ".json":
+		return json.NewEncoder(f).Encode(s)
+	case ".gob":
+		return gob.NewEncoder(f).Encode(s)
+	default:
+		return fmt.Errorf("WriteState: unknown state file type %s", fp)
+	}
+}
+
+// ReadState loads a model state from a .json or .gob file
+func ReadState(fp string) (State, error) {
+	var s State
+	f, err := os.Open(fp)
+	if err != nil {
+		return s, err
+	}
+	defer f.Close()
+	switch mmio.GetExtension(fp) {
+	case ".json":
+		err = json.NewDecoder(f).Decode(&s)
+	case ".gob":
+		err = gob.NewDecoder(f).Decode(&s)
+	default:
+		err = fmt.Errorf("ReadState: unknown state file type %s", fp)
+	}
+	return s, err
+}
+
+// check returns an error if the state does not hold ns stores and nv vectors
+func (s State) check(model string, ns, nv int) error {
+	if len(s.S) != ns || len(s.V) != nv {
+		return fmt.Errorf("%s SetState error: expecting %d stores and %d vectors, got %d and %d", model, ns, nv, len(s.S), len(s.V))
+	}
+	return nil
+}
+
+// checkVector returns an error if the state vector iv is not of length n
+func (s State) checkVector(model string, iv, n int) error {
+	if len(s.V[iv]) != n {
+		return fmt.Errorf("%s SetState error: vector %d expecting length %d, got %d", model, iv, n, len(s.V[iv]))
+	}
+	return nil
+}
+
+func copyVec(v []float64) []float64 {
+	c := make([]float64, len(v))
+	copy(c, v)
+	return c
+}
@@ -0,0 +1,112 @@
+package rainrun
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// same compares simulated values, where NaN matches NaN
+func same(x, y float64) bool {
+	return x == y || math.IsNaN(x) && math.IsNaN(y)
+}
+
+// resumes checks that model b, built like a and set to the state of a halfway through ds, continues identically
+func resumes(t *testing.T, a, b Lumper, ds *Dataset) {
+	t.Helper()
+	h := ds.Ndt / 2
+	for _, v := range ds.FRC[:h] {
+		a.Update(v[0], v[1])
+	}
+	if err := b.SetState(a.State()); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range ds.FRC[h:] {
+		_, qa, _ := a.Update(v[0], v[1])
+		_, qb, _ := b.Update(v[0], v[1])
+		if !same(qa, qb) {
+			t.Fatalf("step %d: runoff %g, resumed %g", h+i+1, qa, qb)
+		}
+	}
+	if !same(a.Storage(), b.Storage()) {
+		t.Errorf("storage %g, resumed %g", a.Storage(), b.Storage())
+	}
+}
+
+func TestStateResume(t *testing.T) {
+	ds := synthetic(400, secPerDay)
+	for _, l := range lumpers() {
+		t.Run(l.name, func(t *testing.T) {
+			a, b := l.new(), l.new()
+			for _, m := range []Lumper{a, b} {
+				if err := m.New(ds, l.params()...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			resumes(t, a, b, ds)
+			if err := b.SetState(State{S: []float64{1.}}); err == nil {
+				t.Error("SetState accepted a state of the wrong size")
+			}
+		})
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	s := State{S: []float64{.1, .2}, V: [][]float64{{.3, .4}, {}}}
+	for _, ext := range []string{".json", ".gob"} {
+		fp := filepath.Join(t.TempDir(), "state"+ext)
+		if err := WriteState(fp, s); err != nil {
+			t.Fatal(err)
+		}
+		r, err := ReadState(fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(r.S, s.S) || !reflect.DeepEqual(r.V[0], s.V[0]) {
+			t.Errorf("%s: read %+v, wrote %+v", ext, r, s)
+		}
+	}
+	if err := WriteState(filepath.Join(t.TempDir(), "state.txt"), s); err == nil {
+		t.Error("expected an error for an unknown state file type")
+	}
+}
+
+// TestCCFStateFile warm-starts a CCF model from a state file written in spring, with snow on the ground
+func TestCCFStateFile(t *testing.T) {
+	const h = 85 // March 27
+	ds := climate(365, secPerDay)
+	p := append(mid((&GR4J{}).Parameters()), .002, 1.1, 0., .3, .0045)
+	for _, ext := range []string{".json", ".gob"} {
+		a, b := &CCFGR4J{}, &CCFGR4J{}
+		for _, m := range []*CCFGR4J{a, b} {
+			if err := m.New(ds, p...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i, v := range ds.FRC[:h] {
+			a.Update(v, ds.DOY[i])
+		}
+		if a.SWE() <= 0. {
+			t.Fatalf("no snowpack at step %d", h)
+		}
+		fp := filepath.Join(t.TempDir(), "state"+ext)
+		if err := WriteState(fp, a.State()); err != nil {
+			t.Fatal(err)
+		}
+		s, err := ReadState(fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := b.SetState(s); err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range ds.FRC[h:] {
+			_, _, qa, _ := a.Update(v, ds.DOY[h+i])
+			_, _, qb, _ := b.Update(v, ds.DOY[h+i])
+			if qa != qb || a.SWE() != b.SWE() {
+				t.Fatalf("%s step %d: runoff %g swe %g, resumed %g %g", ext, h+i+1, qa, a.SWE(), qb, b.SWE())
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package rainrun
+
+import "math"
+
+const secPerDay = 86400.
+
+// tsec returns the dataset timestep [s], defaulting to daily
+func (ds *Dataset) tsec() float64 {
+	if ds == nil || ds.Timestep <= 0. {
+		return secPerDay
+	}
+	return ds.Timestep
+}
+
+// recession converts a recession rate constant k [1/s] to the fraction of storage released over ts seconds
+func recession(k, ts float64) float64 {
+	return 1. - math.Exp(-k*ts)
+}
+
+// stepsPerYear returns the mean number of dataset timesteps per year
+func (ds *Dataset) stepsPerYear() float64 {
+	return 365.25 * secPerDay / ds.tsec()
+}
+
+// Warmup returns the number of timesteps of the first year, discarded when evaluating model performance
+func (ds *Dataset) Warmup() int {
+	n := int(math.Round(ds.stepsPerYear()))
+	if n >= ds.Ndt {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,43 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTimestep(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"default timestep", (*Dataset)(nil).tsec(), secPerDay},
+		{"recession daily", recession(1e-5, secPerDay), 1. - math.Exp(-.864)},
+		{"recession hourly", recession(1e-5, 3600.), 1. - math.Exp(-.036)},
+		{"warmup daily", float64(synthetic(400, secPerDay).Warmup()), 365.},
+		{"warmup hourly", float64(synthetic(400, 3600.).Warmup()), 8766.},
+		{"warmup short", float64(synthetic(300, secPerDay).Warmup()), 0.},
+	} {
+		if math.Abs(c.got-c.want) > 1e-12 {
+			t.Errorf("%s: got %g, want %g", c.name, c.got, c.want)
+		}
+	}
+}
+
+// TestRecessionTimestep drains a linear reservoir for a day at daily and hourly steps
+func TestRecessionTimestep(t *testing.T) {
+	var s [2]float64
+	for i, ts := range []float64{secPerDay, 3600.} {
+		m := &SPLR{}
+		if err := m.New(&Dataset{Timestep: ts}, 1., 1., 1e-5, 1e-5, 1e-5); err != nil {
+			t.Fatal(err)
+		}
+		m.s1 = .1
+		for j := 0; j < int(secPerDay/ts); j++ {
+			m.Update(0., 0.)
+		}
+		s[i] = m.s1
+	}
+	if want := .1 * math.Exp(-.864); math.Abs(s[0]-want) > 1e-12 || math.Abs(s[1]-want) > 1e-12 {
+		t.Errorf("storage after a day: daily %g, hourly %g, want %g", s[0], s[1], want)
+	}
+}
@@ -0,0 +1,145 @@
+package rainrun
+
+import "math"
+
+// TOPMODEL, driven by a topographic index distribution
+// Beven, K.J., M.J. Kirkby, 1979. A physically based, variable contributing area model of basin hydrology. Hydrological Sciences Bulletin 24(1). pp. 43-69.
+// Beven, K.J., 1997. TOPMODEL: a critique. Hydrological Processes 11. pp. 1069-1085.
+// following TMOD9502; the topographic index histogram is read from the dataset location attributes (see Dataset.SetTopoIndex)
+type TOPMODEL struct {
+	ti, af   []float64 // topographic index class values ln(a/tanβ) and area fractions
+	suz, srz []float64 // unsaturated zone storage and root zone deficit of each class
+	sbar     float64   // catchment average saturation deficit
+	lambda   float64   // areal average topographic index
+	szm, szq float64   // recession parameter; baseflow at zero deficit [m/ts]
+	srmax    float64
+	td       float64 // unsaturated zone time delay [ts/m]
+}
+
+// New TOPMODEL constructor
+// [m, lnT0, srmax, td]
+func (m *TOPMODEL) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("TOPMODEL", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if p[0] <= 0. || p[2] <= 0. || p[3] <= 0. {
+		return &ParameterError{"TOPMODEL", "m, srmax and td must be > 0", p}
+	}
+	ti, af, err := ds.TopoIndex()
+	if err != nil {
+		return err
+	}
+	ts := ds.tsec()
+	m.ti, m.af = copyVec(ti), copyVec(af)
+	m.lambda = 0.
+	for i, v := range m.ti {
+		m.lambda += v * m.af[i]
+	}
+	m.szm = p[0]                         // exponential transmissivity decay parameter
+	m.szq = math.Exp(p[1]-m.lambda) * ts // lnT0: log of the saturated transmissivity [m²/s]
+	m.srmax = p[2]                       // root zone available water capacity
+	m.td = p[3] / ts                     // unsaturated zone time delay per unit deficit [ts/m]
+	q0 := math.Max(ds.Obs(0), mingtzero) // initial deficit balancing the observed discharge as baseflow
+	m.sbar = math.Max(-m.szm*math.Log(q0/m.szq), 0.)
+	m.suz, m.srz = make([]float64, len(m.ti)), make([]float64, len(m.ti))
+	return nil
+}
+
+// Update state
+func (m *TOPMODEL) Update(p, ep float64) (float64, float64, float64) {
+	a, qof, qb, g := m.update(p, ep)
+	return a, qof + qb, g
+}
+
+// UpdateFluxes updates state, returning saturation excess overland flow (qof) and baseflow (qb)
+func (m *TOPMODEL) UpdateFluxes(p, ep float64) Fluxes {
+	a, qof, qb, g := m.update(p, ep)
+	var suz, srz float64
+	for i, f := range m.af {
+		suz += m.suz[i] * f
+		srz += m.srz[i] * f
+	}
+	return Fluxes{
+		AET: a, Runoff: qof + qb, Recharge: g,
+		Q: map[string]float64{"qof": qof, "qb": qb},
+		S: map[string]float64{"sbar": m.sbar, "suz": suz, "srz": srz},
+	}
+}
+
+func (m *TOPMODEL) update(p, ep float64) (a, qof, qb, g float64) {
+	for i, f := range m.af {
+		sd := math.Max(m.sbar+m.szm*(m.lambda-m.ti[i]), 0.) // local saturation deficit
+
+		// root zone
+		m.srz[i] -= p
+		if m.srz[i] < 0. {
+			m.suz[i] -= m.srz[i]
+			m.srz[i] = 0.
+		}
+
+		// saturation excess
+		if m.suz[i] > sd {
+			qof += (m.suz[i] - sd) * f
+			m.suz[i] = sd
+		}
+
+		// unsaturated zone drainage to the water table
+		if sd > 0. {
+			uz := math.Min(m.suz[i]/(sd*m.td), m.suz[i])
+			m.suz[i] -= uz
+			g += uz * f
+		}
+
+		// evapotranspiration from the root zone
+		ea := math.Min(ep*(1.-m.srz[i]/m.srmax), m.srmax-m.srz[i])
+		m.srz[i] += ea
+		a += ea * f
+	}
+
+	qb = m.szq * math.Exp(-m.sbar/m.szm)
+	m.sbar += qb - g
+	return
+}
+
+// Storage returns total storage, relative to a catchment saturated to the root zone
+func (m *TOPMODEL) Storage() float64 {
+	s := -m.sbar
+	for i, f := range m.af {
+		s += (m.suz[i] - m.srz[i]) * f
+	}
+	return s
+}
+
+// State returns the current state: catchment average deficit [sbar]; per-class [suz, srz]
+func (m *TOPMODEL) State() State {
+	return State{
+		S: []float64{m.sbar},
+		V: [][]float64{copyVec(m.suz), copyVec(m.srz)},
+	}
+}
+
+// SetState restores a state returned by State; the model must have been built with the same topographic index histogram
+func (m *TOPMODEL) SetState(s State) error {
+	if err := s.check("TOPMODEL", 1, 2); err != nil {
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.checkVector("TOPMODEL", i, len(m.ti)); err != nil {
+			return err
+		}
+	}
+	m.sbar = s.S[0]
+	copy(m.suz, s.V[0])
+	copy(m.srz, s.V[1])
+	return nil
+}
+
+// Parameters describes the TOPMODEL parameters
+func (m *TOPMODEL) Parameters() []Parameter {
+	return []Parameter{
+		{"m", "m", "exponential transmissivity decay parameter", 0., inf, .001, .25, true},
+		{"lnT0", "ln(m²/s)", "log of the saturated transmissivity", -inf, inf, -8., 1., false},
+		{"srmax", "m", "root zone available water capacity", 0., inf, .005, .3, false},
+		{"td", "s/m", "unsaturated zone time delay per unit deficit", 0., inf, 360., 180000., true},
+	}
+}
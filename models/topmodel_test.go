@@ -0,0 +1,19 @@
+package rainrun
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTOPMODEL(t *testing.T) {
+	p := []float64{.02, -10., .1, 80. * secPerDay} // [m, lnT0, srmax, td]
+	szq := math.Exp(-10.-8.) * secPerDay           // baseflow at zero deficit, lnT0 = -10 over a single index class of 8
+	ds := &Dataset{FRC: [][]float64{{0., 0., .001}}, Timestep: secPerDay}
+	ds.SetTopoIndex([]float64{8.}, []float64{1.})
+	// stores [sbar], vectors [suz, srz]
+	checkSteps(t, func() Lumper { return &TOPMODEL{} }, ds, []step{
+		{"drainage to the water table", p, State{S: []float64{.05}, V: [][]float64{{0.}, {0.}}}, .01, 0., 0., szq * math.Exp(-.05/.02), .01 / (.05 * 80.), nil},
+		{"saturation excess", p, State{S: []float64{0.}, V: [][]float64{{0.}, {0.}}}, .01, 0., 0., .01 + szq, 0., nil},
+		{"root zone evaporation", p, State{S: []float64{1.}, V: [][]float64{{0.}, {.05}}}, 0., .004, .002, szq * math.Exp(-1./.02), 0., nil},
+	})
+}
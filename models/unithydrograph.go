@@ -0,0 +1,222 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/maseology/rainrun/routing"
+)
+
+// UnitHydrograph is a calibratable unit hydrograph of the routing library
+type UnitHydrograph interface {
+	Describer
+	Ordinates(p []float64, ts float64) []float64 // ordinates for parameters p and timestep ts [s]
+}
+
+// steps converts a time [d] to timesteps
+func steps(d, ts float64) float64 {
+	return d * secPerDay / ts
+}
+
+// UHGR1 GR4J unit hydrograph UH1
+type UHGR1 struct{}
+
+// Ordinates of the GR4J UH1
+func (UHGR1) Ordinates(p []float64, ts float64) []float64 { return routing.SH1(steps(p[0], ts)) }
+
+// Parameters describes the UHGR1 parameters
+func (UHGR1) Parameters() []Parameter {
+	return []Parameter{{"x4", "d", "unit hydrograph time base", 0., inf, .5, 10., false}}
+}
+
+// UHGR2 GR4J unit hydrograph UH2
+type UHGR2 struct{}
+
+// Ordinates of the GR4J UH2
+func (UHGR2) Ordinates(p []float64, ts float64) []float64 { return routing.SH2(steps(p[0], ts)) }
+
+// Parameters describes the UHGR2 parameters
+func (UHGR2) Parameters() []Parameter {
+	return []Parameter{{"x4", "d", "unit hydrograph half time base", 0., inf, .5, 10., false}}
+}
+
+// UHTriangular HBV triangular MAXBAS unit hydrograph
+type UHTriangular struct{}
+
+// Ordinates of the triangular unit hydrograph
+func (UHTriangular) Ordinates(p []float64, ts float64) []float64 {
+	return routing.Triangular(steps(p[0], ts))
+}
+
+// Parameters describes the UHTriangular parameters
+func (UHTriangular) Parameters() []Parameter {
+	return []Parameter{{"maxbas", "d", "triangular unit hydrograph time base", 0., inf, 1., 7., false}}
+}
+
+// UHNash Nash cascade unit hydrograph
+type UHNash struct{}
+
+// Ordinates of the Nash cascade; n is rounded to the nearest integer
+func (UHNash) Ordinates(p []float64, ts float64) []float64 {
+	return routing.Nash(int(math.Max(math.Round(p[0]), 1.)), steps(p[1], ts))
+}
+
+// Parameters describes the UHNash parameters
+func (UHNash) Parameters() []Parameter {
+	return []Parameter{
+		{"n", "-", "number of linear reservoirs", 1., inf, 1., 6., false},
+		{"k", "d", "reservoir storage constant", 0., inf, .05, 5., true},
+	}
+}
+
+// UHGamma gamma unit hydrograph
+type UHGamma struct{}
+
+// Ordinates of the gamma unit hydrograph
+func (UHGamma) Ordinates(p []float64, ts float64) []float64 {
+	return routing.Gamma(p[0], steps(p[1], ts))
+}
+
+// Parameters describes the UHGamma parameters
+func (UHGamma) Parameters() []Parameter {
+	return []Parameter{
+		{"a", "-", "shape parameter", 0., inf, .5, 10., false},
+		{"theta", "d", "scale parameter", 0., inf, .05, 5., true},
+	}
+}
+
+// UHClark Clark unit hydrograph
+type UHClark struct{}
+
+// Ordinates of the Clark unit hydrograph
+func (UHClark) Ordinates(p []float64, ts float64) []float64 {
+	return routing.Clark(steps(p[0], ts), steps(p[1], ts))
+}
+
+// Parameters describes the UHClark parameters
+func (UHClark) Parameters() []Parameter {
+	return []Parameter{
+		{"tc", "d", "time of concentration", 0., inf, .1, 5., true},
+		{"r", "d", "storage coefficient", 0., inf, .1, 10., true},
+	}
+}
+
+// UHSCS NRCS (SCS) dimensionless unit hydrograph
+type UHSCS struct{}
+
+// Ordinates of the SCS unit hydrograph
+func (UHSCS) Ordinates(p []float64, ts float64) []float64 { return routing.SCS(steps(p[0], ts)) }
+
+// Parameters describes the UHSCS parameters
+func (UHSCS) Parameters() []Parameter {
+	return []Parameter{{"tp", "d", "time to peak", 0., inf, .1, 5., true}}
+}
+
+// Routed routes the runoff of a Lumper through a unit hydrograph.
+// Parameters are ordered [Lumper, UnitHydrograph]; the Lumper must be a Describer.
+type Routed struct {
+	M  Lumper
+	UH UnitHydrograph
+	cv *routing.Convolution
+	nm int
+}
+
+// NewRouted returns Lumper m with its runoff routed through unit hydrograph uh
+func NewRouted(m Lumper, uh UnitHydrograph) *Routed {
+	return &Routed{M: m, UH: uh}
+}
+
+// New builds the Lumper and unit hydrograph from the concatenated parameters p
+func (r *Routed) New(ds *Dataset, p ...float64) error {
+	d, ok := r.M.(Describer)
+	if !ok {
+		return fmt.Errorf("Routed: %T does not describe its parameters", r.M)
+	}
+	r.nm = len(d.Parameters())
+	pu := r.UH.Parameters()
+	if n := r.nm + len(pu); len(p) != n {
+		return &ParameterError{"Routed", fmt.Sprintf("expecting %d parameters", n), p}
+	}
+	for i, par := range pu {
+		if v := p[r.nm+i]; v <= 0. || v < par.Min || v > par.Max {
+			return &ParameterError{"Routed", fmt.Sprintf("unit hydrograph %s must be > 0 and within [%g,%g]", par.Name, par.Min, par.Max), p}
+		}
+	}
+	if err := r.M.New(ds, p[:r.nm]...); err != nil {
+		return err
+	}
+	r.cv = routing.NewConvolution(r.UH.Ordinates(p[r.nm:], ds.tsec()))
+	return nil
+}
+
+// Update state
+func (r *Routed) Update(p, ep float64) (float64, float64, float64) {
+	a, q, g := r.M.Update(p, ep)
+	return a, r.cv.Update(q), g
+}
+
+// UpdateFluxes updates state, adding the unrouted runoff (qu) and the runoff pending release (uh) to the Lumper fluxes
+func (r *Routed) UpdateFluxes(p, ep float64) Fluxes {
+	var fl Fluxes
+	if fm, ok := r.M.(Fluxer); ok {
+		fl = fm.UpdateFluxes(p, ep)
+	} else {
+		fl.AET, fl.Runoff, fl.Recharge = r.M.Update(p, ep)
+		fl.Q, fl.S = make(map[string]float64), make(map[string]float64)
+	}
+	fl.Q["qu"] = fl.Runoff
+	fl.Runoff = r.cv.Update(fl.Runoff)
+	fl.S["uh"] = r.cv.Storage()
+	return fl
+}
+
+// Storage returns total storage, including runoff pending release
+func (r *Routed) Storage() float64 {
+	if r.cv == nil {
+		return r.M.Storage()
+	}
+	return r.M.Storage() + r.cv.Storage()
+}
+
+// Parameters describes the Lumper parameters, followed by the unit hydrograph parameters
+func (r *Routed) Parameters() []Parameter {
+	var ps []Parameter
+	if d, ok := r.M.(Describer); ok {
+		ps = d.Parameters()
+	}
+	return append(ps, r.UH.Parameters()...)
+}
+
+// State returns the Lumper state, with the runoff pending release appended to its vectors
+func (r *Routed) State() State {
+	s := r.M.State()
+	s.V = append(s.V, r.cv.Pending())
+	return s
+}
+
+// SetState restores a state returned by State
+func (r *Routed) SetState(s State) error {
+	n := len(s.V)
+	if n == 0 {
+		return fmt.Errorf("Routed SetState error: missing unit hydrograph vector")
+	}
+	v := s.V[n-1]
+	s.V = s.V[:n-1]
+	if n == 1 {
+		s.V = nil
+	}
+	if err := r.M.SetState(s); err != nil {
+		return err
+	}
+	return r.cv.SetPending(v)
+}
+
+// Exchange returns the external exchange of the Lumper's last update
+func (r *Routed) Exchange() float64 {
+	return Exchange(r.M)
+}
+
+// Err returns the Lumper's numerical error
+func (r *Routed) Err() error {
+	return UpdateError(r.M)
+}
@@ -0,0 +1,80 @@
+package rainrun
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestRoutedClosure(t *testing.T) {
+	for _, ts := range []float64{secPerDay, 3600.} {
+		ds := synthetic(400, ts)
+		for nam, fuh := range uhs {
+			t.Run(fmt.Sprintf("%s/%.0fs", nam, ts), func(t *testing.T) {
+				r := NewRouted(&HBV{}, fuh())
+				closes(t, r, ds, mid(r.Parameters())...)
+			})
+		}
+	}
+}
+
+func TestRoutedConvolves(t *testing.T) {
+	ds := synthetic(100, secPerDay)
+	p := mid((&HBV{}).Parameters())
+	m, r := &HBV{}, NewRouted(&HBV{}, UHTriangular{})
+	if err := m.New(ds, p...); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.New(ds, append(p, 4.)...); err != nil { // maxbas = 4 d
+		t.Fatal(err)
+	}
+	uh, qs := []float64{.125, .375, .375, .125}, make([]float64, 0, ds.Ndt)
+	for k, v := range ds.FRC {
+		_, q, _ := m.Update(v[0], v[1])
+		_, qr, _ := r.Update(v[0], v[1])
+		qs = append(qs, q)
+		want := 0.
+		for j, u := range uh {
+			if k-j >= 0 {
+				want += u * qs[k-j]
+			}
+		}
+		if math.Abs(qr-want) > 1e-12 {
+			t.Fatalf("step %d: routed %g, want %g", k+1, qr, want)
+		}
+	}
+}
+
+func TestUnitHydrographTimestep(t *testing.T) {
+	for nam, fuh := range uhs {
+		if nam == "Clark" { // routed through a linear reservoir stepped at the timestep, not discretized from an S-curve
+			continue
+		}
+		uh := fuh()
+		p := mid(uh.Parameters())
+		d, h := uh.Ordinates(p, secPerDay), uh.Ordinates(p, 3600.)
+		if len(h) < len(d) {
+			t.Errorf("%s: %d hourly ordinates, fewer than the %d daily", nam, len(h), len(d))
+		}
+		for i, v := range d { // daily ordinates are sums of the hourly ones
+			s := 0.
+			for j := 24 * i; j < 24*(i+1) && j < len(h); j++ {
+				s += h[j]
+			}
+			if math.Abs(s-v) > 1e-3 {
+				t.Errorf("%s: day %d ordinate %.4f, hourly sum %.4f", nam, i+1, v, s)
+				break
+			}
+		}
+	}
+}
+
+func TestRoutedParameters(t *testing.T) {
+	r := NewRouted(&GR4J{}, UHGamma{})
+	if err := r.New(synthetic(10, secPerDay), append(mid((&GR4J{}).Parameters()), 0., 1.)...); err == nil {
+		t.Error("expected an error for a zero gamma shape")
+	}
+	if err := r.New(synthetic(10, secPerDay), mid((&GR4J{}).Parameters())...); err == nil {
+		t.Error("expected an error for missing unit hydrograph parameters")
+	}
+}
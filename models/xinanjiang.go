@@ -0,0 +1,199 @@
+package rainrun
+
+import "math"
+
+// Xinanjiang (XAJ) model: three-layer tension water store with a distributed capacity curve,
+// free water store separating surface runoff, interflow and groundwater
+// Zhao, R.J., 1992. The Xinanjiang model applied in China. Journal of Hydrology 135. pp. 371-381.
+// storages are held as depths over the pervious area; the free water store over the runoff-producing area (fr)
+type Xinanjiang struct {
+	wu, wl, wd, s, fr float64 // upper/lower/deep tension water, free water and the runoff-producing area fraction
+	inf, gw           res     // interflow and groundwater stores
+	ke, b, im, c      float64
+	wum, wlm, wdm, sm float64
+	ex, kif, kgf      float64
+}
+
+// New Xinanjiang constructor
+// [ke, b, im, wum, wlm, wdm, c, sm, ex, ki, kg, ci, cg]
+func (m *Xinanjiang) New(ds *Dataset, p ...float64) error {
+	if err := checkCount("Xinanjiang", p, len(m.Parameters())); err != nil {
+		return err
+	}
+	if p[0] < 0. || p[1] < 0. || fracCheck(p[2]) || p[2] == 1. || p[3] <= 0. || p[4] <= 0. || p[5] < 0. || fracCheck(p[6]) || p[7] <= 0. || p[8] < 0. {
+		return &ParameterError{"Xinanjiang", "ke, b, ex and wdm must be >= 0, wum, wlm and sm > 0, im must be [0,1), c must be [0,1]", p}
+	}
+	if p[9] < 0. || p[10] < 0. || p[11] < 0. || p[12] < 0. {
+		return &ParameterError{"Xinanjiang", "ki, kg, ci and cg must be >= 0", p}
+	}
+	ts := ds.tsec()
+	m.ke, m.b, m.im = p[0], p[1], p[2] // PET ratio, capacity curve exponent, impervious fraction
+	m.wum, m.wlm, m.wdm = p[3], p[4], p[5]
+	m.c = p[6]              // deep layer evapotranspiration coefficient
+	m.sm, m.ex = p[7], p[8] // free water capacity and its capacity curve exponent
+	if k := p[9] + p[10]; k > 0. {
+		kt := recession(k, ts) // free water outflow, split among interflow and groundwater
+		m.kif, m.kgf = kt*p[9]/k, kt*p[10]/k
+	} else {
+		m.kif, m.kgf = 0., 0.
+	}
+	m.inf.new(math.MaxFloat64, recession(p[11], ts))
+	m.gw.new(math.MaxFloat64, recession(p[12], ts))
+	m.wu, m.wl, m.wd, m.s, m.fr = 0., 0., 0., 0., 0.
+	m.inf.sto, m.gw.sto = 0., 0.
+	return nil
+}
+
+// Update state
+func (m *Xinanjiang) Update(p, ep float64) (float64, float64, float64) {
+	a, qimp, rs, qi, qg, g := m.update(p, ep)
+	return a, qimp + rs + qi + qg, g
+}
+
+// UpdateFluxes updates state, returning impervious runoff (qimp), surface runoff (rs), interflow (qi) and groundwater discharge (qg)
+func (m *Xinanjiang) UpdateFluxes(p, ep float64) Fluxes {
+	a, qimp, rs, qi, qg, g := m.update(p, ep)
+	return Fluxes{
+		AET: a, Runoff: qimp + rs + qi + qg, Recharge: g,
+		Q: map[string]float64{"qimp": qimp, "rs": rs, "qi": qi, "qg": qg},
+		S: map[string]float64{"wu": m.wu, "wl": m.wl, "wd": m.wd, "s": m.s * m.fr, "inf": m.inf.sto, "gw": m.gw.sto},
+	}
+}
+
+func (m *Xinanjiang) update(p, ep float64) (a, qimp, rs, qi, qg, g float64) {
+	ep *= m.ke
+
+	// three-layer evapotranspiration
+	var eu, el, ed float64
+	if m.wu+p >= ep {
+		eu = ep
+	} else {
+		eu = m.wu + p
+		rem := ep - eu
+		switch {
+		case m.wl >= m.c*m.wlm:
+			el = math.Min(rem*m.wl/m.wlm, m.wl)
+		case m.wl >= m.c*rem:
+			el = m.c * rem
+		default:
+			el = m.wl
+			ed = math.Min(m.c*rem-el, m.wd)
+		}
+	}
+	e := eu + el + ed
+	pe := p - e // net rainfall
+
+	// runoff generation over the pervious area, tension water capacity curve
+	var r float64
+	if pe > 0. {
+		wm := m.wum + m.wlm + m.wdm
+		w := m.wu + m.wl + m.wd
+		wmm := wm * (1. + m.b)
+		au := wmm * (1. - math.Pow(math.Max(1.-w/wm, 0.), 1./(1.+m.b)))
+		if pe+au < wmm {
+			r = pe - wm + w + wm*math.Pow(1.-(pe+au)/wmm, 1.+m.b)
+		} else {
+			r = pe - (wm - w)
+		}
+		r = math.Max(r, 0.)
+
+		// infiltration fills the tension layers from the top
+		x := pe - r
+		m.wu += x
+		if m.wu > m.wum {
+			m.wl += m.wu - m.wum
+			m.wu = m.wum
+			if m.wl > m.wlm {
+				m.wd += m.wl - m.wlm
+				m.wl = m.wlm
+				if m.wd > m.wdm { // rounding only
+					r += m.wd - m.wdm
+					m.wd = m.wdm
+				}
+			}
+		}
+	} else {
+		m.wu += p - eu
+		m.wl -= el
+		m.wd -= ed
+	}
+
+	// runoff separation through the free water store
+	if r > mingtzero {
+		fr := r / pe
+		s := m.s * m.fr / fr // free water depth re-scaled to the new runoff-producing area
+		if s > m.sm {
+			rs = (s - m.sm) * fr
+			s = m.sm
+		}
+		smm := m.sm * (1. + m.ex)
+		au := smm * (1. - math.Pow(1.-s/m.sm, 1./(1.+m.ex)))
+		var rc float64
+		if pe+au < smm {
+			rc = fr * (pe - m.sm + s + m.sm*math.Pow(1.-(pe+au)/smm, 1.+m.ex))
+		} else {
+			rc = fr * (pe + s - m.sm)
+		}
+		rc = math.Max(rc, 0.)
+		m.s, m.fr = s+pe-rc/fr, fr
+		if m.s > m.sm { // rounding only
+			rc += (m.s - m.sm) * fr
+			m.s = m.sm
+		}
+		rs += rc
+	}
+	ri, rg := m.kif*m.s*m.fr, m.kgf*m.s*m.fr
+	if m.fr > 0. {
+		m.s -= (ri + rg) / m.fr
+	}
+
+	// pervious area fluxes to the whole catchment; routing of interflow and groundwater
+	fp := 1. - m.im
+	rs *= fp
+	g = rg * fp
+	m.inf.update(ri * fp)
+	m.gw.update(g)
+	qi, qg = m.inf.decayExp(), m.gw.decayExp()
+	qimp = m.im * math.Max(p-ep, 0.)
+	a = fp*e + m.im*math.Min(p, ep)
+	return
+}
+
+// Storage returns total storage
+func (m *Xinanjiang) Storage() float64 {
+	return (1.-m.im)*(m.wu+m.wl+m.wd+m.s*m.fr) + m.inf.sto + m.gw.sto
+}
+
+// State returns the current state [wu, wl, wd, s, fr, inf, gw]
+func (m *Xinanjiang) State() State {
+	return State{S: []float64{m.wu, m.wl, m.wd, m.s, m.fr, m.inf.sto, m.gw.sto}}
+}
+
+// SetState restores a state returned by State
+func (m *Xinanjiang) SetState(s State) error {
+	if err := s.check("Xinanjiang", 7, 0); err != nil {
+		return err
+	}
+	m.wu, m.wl, m.wd, m.s, m.fr = s.S[0], s.S[1], s.S[2], s.S[3], s.S[4]
+	m.inf.sto, m.gw.sto = s.S[5], s.S[6]
+	return nil
+}
+
+// Parameters describes the Xinanjiang model parameters
+func (m *Xinanjiang) Parameters() []Parameter {
+	return []Parameter{
+		{"ke", "-", "ratio of potential evapotranspiration to PET input", 0., inf, .5, 1.5, false},
+		{"b", "-", "tension water capacity curve exponent", 0., inf, .1, .6, false},
+		{"im", "-", "impervious area fraction", 0., 1., 0., .05, false},
+		{"wum", "m", "upper layer tension water capacity", 0., inf, .005, .05, false},
+		{"wlm", "m", "lower layer tension water capacity", 0., inf, .05, .15, false},
+		{"wdm", "m", "deep layer tension water capacity", 0., inf, .01, .1, false},
+		{"c", "-", "deep layer evapotranspiration coefficient", 0., 1., .05, .2, false},
+		{"sm", "m", "free water storage capacity", 0., inf, .005, .1, false},
+		{"ex", "-", "free water capacity curve exponent", 0., inf, .5, 2., false},
+		{"ki", "1/s", "free water outflow rate to interflow", 0., inf, 1.2e-6, 9.2e-6, true},
+		{"kg", "1/s", "free water outflow rate to groundwater", 0., inf, 1.2e-6, 9.2e-6, true},
+		{"ci", "1/s", "interflow recession rate", 0., inf, 1.2e-6, 8e-6, true},
+		{"cg", "1/s", "groundwater recession rate", 0., inf, 2.3e-8, 2.3e-7, true},
+	}
+}
@@ -0,0 +1,18 @@
+package rainrun
+
+import "testing"
+
+func TestXinanjiang(t *testing.T) {
+	// [ke, b, im, wum, wlm, wdm, c, sm, ex, ki, kg, ci, cg], uniform capacities without free water outflow
+	par := func(im float64) []float64 {
+		return []float64{1., 0., im, .02, .08, .05, .1, .02, 0., 0., 0., 0., 0.}
+	}
+	// stores [wu, wl, wd, s, fr, inf, gw]
+	checkSteps(t, func() Lumper { return &Xinanjiang{} }, &Dataset{Timestep: secPerDay}, []step{
+		{"upper layer evaporation", par(0.), State{S: []float64{.01, .04, .05}}, 0., .004, .004, 0., 0., []float64{.006, .04, .05}},
+		{"lower layer evaporation", par(0.), State{S: []float64{.001, .04, .05}}, 0., .005, .003, 0., 0., []float64{0., .038, .05}},
+		{"deep layer evaporation", par(0.), State{S: []float64{0., .0002, .05}}, 0., .005, .0005, 0., 0., []float64{0., 0., .0497}},
+		{"tension water fills and runs off", par(0.), State{S: []float64{.02, .08, .04}}, .03, 0., 0., .01 * 2. / 3., 0., []float64{.02, .08, .05}},
+		{"impervious runoff", par(.1), State{S: []float64{.01, .04, .05}}, .01, .004, .004, .1 * .006, 0., []float64{.016, .04, .05}},
+	})
+}
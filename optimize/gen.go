@@ -1,122 +1,45 @@
 package optimize
 
 import (
-	"log"
 	"math"
 
-	rainrun "github.com/maseology/rainrun/models"
 	rr "github.com/maseology/rainrun/models"
-	"github.com/maseology/rainrun/sample"
 )
 
-func eval(m rr.Lumper) float64 { // evaluate model
-	o := make([]float64, rr.Ndt)
-	s := make([]float64, rr.Ndt)
-	for i, v := range rr.FRC {
-		_, r, _ := m.Update(v[0], v[1])
-		o[i] = v[2]
+func eval(ds *rr.Dataset, m rr.Model) float64 { // evaluate model
+	o := make([]float64, ds.Ndt)
+	s := make([]float64, ds.Ndt)
+	for i := range ds.FRC {
+		_, _, r, _ := rr.Step(ds, m, i)
+		o[i] = ds.Obs(i)
 		s[i] = r
 	}
-	return minimizer(o[365:], s[365:])
-}
-
-func genAtkinson(u []float64) float64 {
-	var m rr.Lumper = &rr.Atkinson{}
-	m.New(sample.Atkinson(u)...)
-	f := eval(m)
-	if math.IsNaN(f) {
-		log.Fatalf("Objective function error, u: %v\n", u)
-	}
-	return f
-}
-
-func genDawdyODonnell(u []float64) float64 {
-	var m rr.Lumper = &rr.DawdyODonnell{}
-	m.New(sample.DawdyODonnell(u, rr.Timestep)...)
-	f := eval(m)
-	if math.IsNaN(f) {
-		log.Fatalf("Objective function error, u: %v\n", u)
-	}
-	return f
-}
-
-func genGR4J(u []float64) float64 {
-	var m rr.Lumper = &rr.GR4J{}
-	m.New(sample.GR4J(u)...)
-	f := eval(m)
-	if math.IsNaN(f) {
-		log.Fatalf("Objective function error, u: %v\n", u)
-	}
-	return f
-}
-
-// func genGR4J(u []float64) float64 {
-// 	var m rr.Lumper = &rr.GR4J{}
-// 	ss := sampler.NewSet(sample.GR4J()) //////////////////////////////////  TO FIX
-// 	m.New(ss.Sample(u)...)
-// 	f := eval(m)
-// 	if math.IsNaN(f) {
-// 		log.Fatalf("Objective function error, u: %v\n", u)
-// 	}
-// 	return f
-// }
-
-func genHBV(u []float64) float64 {
-	var m rr.Lumper = &rr.HBV{}
-	m.New(sample.HBV(u, rainrun.Timestep)...)
-	f := eval(m)
-	if math.IsNaN(f) {
-		log.Fatalf("Objective function error, u: %v\n", u)
-	}
-	return f
-}
-
-func genManabeGW(u []float64) float64 {
-	var m rr.Lumper = &rr.ManabeGW{}
-	m.New(sample.ManabeGW(u)...)
-	f := eval(m)
-	if math.IsNaN(f) {
-		log.Fatalf("Objective function error, u: %v\n", u)
-	}
-	return f
-}
-
-func genMultiLayerCapacitance(u []float64) float64 {
-	var m rr.Lumper = &rr.MultiLayerCapacitance{}
-	m.New(sample.MultiLayerCapacitance(u)...)
-	f := eval(m)
-	if math.IsNaN(f) {
-		log.Fatalf("Objective function error, u: %v\n", u)
-	}
-	return f
-}
-
-func genQuinn(u []float64) float64 {
-	var m rr.Lumper = &rr.Quinn{}
-	m.New(sample.Quinn(u)...)
-	f := eval(m)
-	if math.IsNaN(f) {
-		log.Fatalf("Objective function error, u: %v\n", u)
+	if rr.UpdateError(m) != nil {
+		return infeasible
 	}
-	return f
+	w := ds.Warmup()
+	return minimizer(o[w:], s[w:])
 }
 
-func genSIXPAR(u []float64) float64 {
-	var m rr.Lumper = &rr.SIXPAR{}
-	m.New(sample.SIXPAR(u)...)
-	f := eval(m)
-	if math.IsNaN(f) {
-		log.Fatalf("Objective function error, u: %v\n", u)
+// gen returns the objective function of a registered model
+func gen(ds *rr.Dataset, e rr.Entry) func(u []float64) float64 {
+	return func(u []float64) float64 {
+		m := balanced(e.New())
+		if err := m.New(ds, e.Sample(u)...); err != nil {
+			return infeasible
+		}
+		f := eval(ds, m)
+		if math.IsNaN(f) {
+			return infeasible
+		}
+		return f
 	}
-	return f
 }
 
-func genSPLR(u []float64) float64 {
-	var m rr.Lumper = &rr.SPLR{}
-	m.New(sample.SPLR(u)...)
-	f := eval(m)
-	if math.IsNaN(f) {
-		log.Fatalf("Objective function error, u: %v\n", u)
+// balanced wraps Lumpers in a mass-balance check when BalanceTol is set
+func balanced(m rr.Model) rr.Model {
+	if l, ok := m.(rr.Lumper); ok && BalanceTol > 0. {
+		return rr.NewBalance(l, BalanceTol)
 	}
-	return f
+	return m
 }
@@ -17,16 +17,26 @@ import (
 )
 
 const (
-	nrbf   = 100
-	ncmplx = 200
+	nrbf       = 100
+	ncmplx     = 200
+	infeasible = 9999. // objective function value given to infeasible parameter sets
 )
 
 var minimizer = func(o, s []float64) float64 { return 1. - objfunc.NSE(o, s) }
 
+// BalanceTol, when positive, wraps every Lumper calibrated by Optimize in a
+// mass-balance check; parameter sets leaking more than BalanceTol [m] in any
+// timestep are scored as infeasible
+var BalanceTol = 0.
+
+// UTMZone of the forcing location coordinates, used by models computing solar radiation
+var UTMZone = 17
+
 // Optimize a single or set of rainrun models
 func Optimize(fp, mdl, logfp string) {
 	logger := mmio.GetInstance(logfp)
-	rr.LoadMET(fp, true)
+	ds := rr.LoadMET(fp, true)
+	ds.UTMZone = UTMZone
 
 	rng := rand.New(mrg63k3a.New())
 	rng.Seed(time.Now().UnixNano())
@@ -34,135 +44,34 @@ func Optimize(fp, mdl, logfp string) {
 	const nrbf = 100
 	ncmplx := 64
 
-	switch mdl {
-	case "Atkinson":
-		func() {
-			uFinal, _ := glbopt.SCE(ncmplx, 7, rng, genAtkinson, true)
-			// uFinal, _ := glbopt.SurrogateRBF(nrbf, 7, rng, genAtkinson)
-
-			var m rr.Lumper = &rr.Atkinson{}
-			pFinal := sample.Atkinson(uFinal)
-			fmt.Printf("\nfinal parameters: %v\n", pFinal)
-			fmt.Printf("sample space:\t%f\n", uFinal)
-			m.New(pFinal...)
-			rr.EvalPNG(m)
-		}()
-	case "DawdyODonnell":
-		func() {
-			if rr.Timestep <= 0. {
-				log.Fatalf("need to set timestep length for Dawdy O'Donnell simulations")
-			}
-			uFinal, _ := glbopt.SCE(ncmplx, 6, rng, genDawdyODonnell, true)
-			// uFinal, _ := glbopt.SurrogateRBF(nrbf, 6, rng, genDawdyODonnell)
-
-			var m rr.Lumper = &rr.DawdyODonnell{}
-			pFinal := sample.DawdyODonnell(uFinal, rr.Timestep)
-			fmt.Printf("\nfinal parameters: %v\n", pFinal)
-			fmt.Printf("sample space:\t%f\n", uFinal)
-			m.New(pFinal...)
-			rr.EvalPNG(m)
-		}()
-	case "GR4J":
-		func() {
-			uFinal, _ := glbopt.SCE(ncmplx, 4, rng, genGR4J, true)
-			// uFinal, _ := glbopt.SurrogateRBF(nrbf, 4, rng, genGR4J)
-
-			var m rr.Lumper = &rr.GR4J{}
-			pFinal := sample.GR4J(uFinal)
-			sp := fmt.Sprintf("\nfinal parameters:\t%.3e\n", pFinal)
-			su := fmt.Sprintf("sample space:\t\t%f\n", uFinal)
-			fmt.Print(sp + su)
-			m.New(pFinal...)
-			logger.Println(mmio.FileName(fp, false))
-			logger.Print(sp + su)
-			logger.Println("\n" + rr.EvalPNG(m))
-			// var m rr.Lumper = &rr.GR4J{}
-			// ss := sampler.NewSet(sample.GR4J()) //////////////////////////////////  TO FIX
-			// pFinal := ss.Sample(uFinal)
-			// fmt.Printf("\nfinal parameters: %v\n", pFinal)
-			// m.New(pFinal...)
-			// rr.EvalPNG(m)
-		}()
-	case "HBV":
-		func() {
-			if rr.Timestep <= 0. {
-				// log.Fatalf("need to set timestep length for HBV simulations")
-				rr.Timestep = 86400.
-			}
-			uFinal, _ := glbopt.SCE(ncmplx, 9, rng, genHBV, true)
-			// uFinal, _ := glbopt.SurrogateRBF(nrbf, 9, rng, genHBV)
-
-			var m rr.Lumper = &rr.HBV{}
-			pFinal := sample.HBV(uFinal, rr.Timestep)
-			sp := fmt.Sprintf("\nfinal parameters:\t%.3e\n", pFinal)
-			su := fmt.Sprintf("sample space:\t\t%f\n", uFinal)
-			m.New(pFinal...)
-			logger.Println(mmio.FileName(fp, false))
-			logger.Print(sp + su)
-			logger.Println("\n" + rr.EvalPNG(m))
-		}()
-	case "ManabeGW":
-		func() { // check
-			uFinal, _ := glbopt.SCE(ncmplx, 5, rng, genManabeGW, true)
-			// uFinal, _ := glbopt.SurrogateRBF(nrbf, 5, rng, genManabeGW)
-
-			var m rr.Lumper = &rr.ManabeGW{}
-			pFinal := sample.ManabeGW(uFinal)
-			fmt.Printf("\nfinal parameters: %v\n", pFinal)
-			fmt.Printf("sample space:\t\t%f\n", uFinal)
-			m.New(pFinal...)
-			rr.EvalPNG(m)
-		}()
-	case "MultiLayerCapacitance":
-		func() { // check
-			uFinal, _ := glbopt.SCE(ncmplx, 9, rng, genMultiLayerCapacitance, true)
-			// uFinal, _ := glbopt.SurrogateRBF(nrbf, 9, rng, genMultiLayerCapacitance)
-
-			var m rr.Lumper = &rr.MultiLayerCapacitance{}
-			pFinal := sample.MultiLayerCapacitance(uFinal)
-			fmt.Printf("\nfinal parameters: %v\n", pFinal)
-			fmt.Printf("sample space:\t\t%f\n", uFinal)
-			m.New(pFinal...)
-			rr.EvalPNG(m)
-		}()
-	case "Quinn":
-		func() { // check
-			uFinal, _ := glbopt.SCE(ncmplx, 11, rng, genQuinn, true)
-			// uFinal, _ := glbopt.SurrogateRBF(nrbf, 11, rng, genQuinn)
-
-			var m rr.Lumper = &rr.Quinn{}
-			pFinal := sample.Quinn(uFinal)
-			fmt.Printf("\nfinal parameters: %v\n", pFinal)
-			fmt.Printf("sample space:\t\t%f\n", uFinal)
-			m.New(pFinal...)
-			rr.EvalPNG(m)
-		}()
-	case "SIXPAR":
-		func() { // check
-			uFinal, _ := glbopt.SCE(ncmplx, 6, rng, genSIXPAR, true)
-			// uFinal, _ := glbopt.SurrogateRBF(nrbf, 6, rng, genSIXPAR)
+	e, ok := rr.Lookup(mdl)
+	if !ok {
+		fmt.Println("unrecognized model:" + mdl)
+		return
+	}
+	if _, ok := e.New().(rr.Monthly); ok {
+		var err error
+		if ds, err = ds.ToMonthly(); err != nil { // monthly models are forced by monthly totals
+			log.Fatalf("%v", err)
+		}
+	}
 
-			var m rr.Lumper = &rr.SIXPAR{}
-			pFinal := sample.SIXPAR(uFinal)
-			fmt.Printf("\nfinal parameters: %v\n", pFinal)
-			fmt.Printf("sample space:\t\t%f\n", uFinal)
-			m.New(pFinal...)
-			rr.EvalPNG(m)
-		}()
-	case "SPLR":
-		func() { // check (negative AET)
-			uFinal, _ := glbopt.SCE(ncmplx, 6, rng, genSPLR, true)
-			// uFinal, _ := glbopt.SurrogateRBF(nrbf, 6, rng, genSPLR)
+	uFinal, _ := glbopt.SCE(ncmplx, e.Ndim, rng, gen(ds, e), true)
+	// uFinal, _ := glbopt.SurrogateRBF(nrbf, e.Ndim, rng, gen(ds, e))
 
-			var m rr.Lumper = &rr.SPLR{}
-			pFinal := sample.SPLR(uFinal)
-			fmt.Printf("\nfinal parameters: %v\n", pFinal)
-			fmt.Printf("sample space:\t\t%f\n", uFinal)
-			m.New(pFinal...)
-			rr.EvalPNG(m)
-		}()
-	default:
-		fmt.Println("unrecognized model:" + mdl)
+	m := balanced(e.New())
+	pFinal := e.Sample(uFinal)
+	sp := fmt.Sprintf("\nfinal parameters:\t%.3e\n", pFinal)
+	su := fmt.Sprintf("sample space:\t\t%f\n", uFinal)
+	fmt.Print(sp + su)
+	if err := m.New(ds, pFinal...); err != nil {
+		log.Fatalf("%v", err)
+	}
+	logger.Println(mmio.FileName(fp, false))
+	logger.Print(sp + su)
+	logger.Println("\n" + rr.EvalPNG(ds, m))
+	if b, ok := m.(*rr.Balance); ok {
+		logger.Print(b)
 	}
 }
 
@@ -170,12 +79,16 @@ func Optimize(fp, mdl, logfp string) {
 // every possible permutation of p dimensions and w discrete
 // values.
 func permute(fp string) {
-	rr.LoadMET(fp, true)
-	var m rr.Lumper = &rr.DawdyODonnell{}
+	ds := rr.LoadMET(fp, true)
+	ds.UTMZone = UTMZone
+	var m rr.Model = &rr.DawdyODonnell{}
 	for i, u := range smpln.Permutations(6, 3) {
 		fmt.Println(i, u)
-		m.New(sample.DawdyODonnell(u, rr.Timestep)...)
-		if math.IsNaN(eval(m)) {
+		if err := m.New(ds, sample.DawdyODonnell(u)...); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if math.IsNaN(eval(ds, m)) {
 			panic("NaN")
 		}
 	}
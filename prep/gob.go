@@ -11,9 +11,12 @@ import (
 	"github.com/maseology/goHydro/grid"
 	"github.com/maseology/goHydro/tem"
 	"github.com/maseology/mmio"
+	rr "github.com/maseology/rainrun/models"
 	"github.com/maseology/rdrr/model"
 )
 
+const nTIbins = 30 // number of topographic index histogram classes
+
 func CreateMetGob(gobdir, gagfp, frcFP string, cid0 int) {
 
 	gdefFP := "M:/Peel/RDRR-PWRMM21/dat/elevation.real_SWS10.indx.gdef"
@@ -44,7 +47,7 @@ func CreateMetGob(gobdir, gagfp, frcFP string, cid0 int) {
 		return dts, qs
 	}()
 
-	vals, ca := func(dts []time.Time) ([][]float64, float64) {
+	vals, ca, loc := func(dts []time.Time) ([][]float64, float64, []float64) {
 
 		// get grid definition
 		fmt.Println("\ncollecting forcings..")
@@ -77,84 +80,22 @@ func CreateMetGob(gobdir, gagfp, frcFP string, cid0 int) {
 
 		// get catchment cells
 		fmt.Println("collecting catchment cells")
-		cids := func() []int {
+		cids, loc := func() ([]int, []float64) {
 			var dem tem.TEM
 			if err := dem.New(demFP); err != nil {
 				log.Fatalf(" tem.New() error: %v", err)
 			}
-			return dem.ContributingAreaIDs(cid0)
-		}()
+			cids := dem.ContributingAreaIDs(cid0)
 
-		// get met IDs
-		fmt.Println("collecting meterological IDs")
-		fmid := func(cids []int) map[int]float64 {
-			mc := make(map[int]int)
-			for _, cid := range cids {
-				if mid, ok := frc.XR[cid]; ok {
-					if _, ok := mc[mid]; ok {
-						mc[mid]++
-					} else {
-						mc[mid] = 1
-					}
-				} // else {
-				// 	log.Fatalf("error finding met IDs")
-				// }
-			}
-			fmc, dnm := make(map[int]float64, len(mc)), 0.
-			for i, v := range mc {
-				fv := float64(v)
-				fmc[i] = fv
-				dnm += fv
-			}
-			for i, v := range fmc {
-				fmc[i] = v / dnm
-			}
-			return fmc
-		}(cids)
+			// location attributes [id, x, y, z, gradient, aspect, area] of the outlet, carrying the catchment topographic index histogram (see rainrun.Dataset.SetTopoIndex)
+			xy, t := gd.Coord[cid0], TEM{&dem}
+			z, g, a := t.Surface(cid0)
+			ds := rr.Dataset{Loc: []float64{float64(cid0), xy.X, xy.Y, z, g, a, gd.CellArea() * float64(len(cids))}}
+			ds.SetTopoIndex(TopoIndex(t, cids, math.Sqrt(gd.CellArea()), nTIbins))
+			return cids, ds.Loc
+		}()
 
-		dxr := make(map[time.Time]int, len(dts))
-		for i, t := range dts {
-			dxr[t] = i
-		}
-		// if npar == 2 {
-		// 	p, ep := make([]float64, len(dts)), make([]float64, len(dts))
-		// 	for i, t := range frc.T {
-		// 		d := mmio.DayDate(t)
-		// 		if ii, ok := dxr[d]; !ok {
-		// 			continue
-		// 		} else {
-		// 			for mid, w := range fmid {
-		// 				p[ii] += frc.D[0][mid][i] * w
-		// 				ep[ii] += frc.D[1][mid][i] * w
-		// 			}
-		// 		}
-		// 	}
-		// 	return [][]float64{p, ep}, gd.CellArea() * float64(len(cids))
-		// }
-		vs := make([][]float64, len(dts))
-		// n := make([]float64, len(dts))
-		for i := 0; i < len(dts); i++ {
-			vs[i] = make([]float64, npar)
-		}
-		for i, t := range frc.T {
-			d := mmio.DayDate(t)
-			if ii, ok := dxr[d]; !ok {
-				continue
-			} else {
-				for mid, w := range fmid {
-					for k := 0; k < npar; k++ {
-						vs[ii][k] += frc.D[k][mid][i] * w
-					}
-				}
-				// n[ii]++
-			}
-		}
-		// for ii := range n {
-		// 	for k := 0; k < npar; k++ {
-		// 		vs[ii][k] /= n[ii]
-		// 	}
-		// }
-		return vs, gd.CellArea() * float64(len(cids))
+		return CatchmentForcings(frc, cids, dts, npar), gd.CellArea() * float64(len(cids)), loc
 	}(dts)
 
 	dat := make([][]float64, len(dts))
@@ -169,10 +110,14 @@ func CreateMetGob(gobdir, gagfp, frcFP string, cid0 int) {
 	if err != nil {
 		log.Fatalf("createMetGob saveGob error: %v", err)
 	}
-	if err := gob.NewEncoder(f).Encode(dat); err != nil {
+	enc := gob.NewEncoder(f) // a single stream, as decoded by rainrun.LoadMET
+	if err := enc.Encode(dat); err != nil {
+		log.Fatalf("createMetGob saveGob error: %v", err)
+	}
+	if err := enc.Encode(dts); err != nil {
 		log.Fatalf("createMetGob saveGob error: %v", err)
 	}
-	if err := gob.NewEncoder(f).Encode(dts); err != nil {
+	if err := enc.Encode(loc); err != nil {
 		log.Fatalf("createMetGob saveGob error: %v", err)
 	}
 }
@@ -2,6 +2,7 @@ package prep
 
 import (
 	"log"
+	"time"
 
 	"github.com/maseology/goHydro/grid"
 	"github.com/maseology/goHydro/met"
@@ -59,3 +60,61 @@ func loadMetFORC(toGD *grid.Definition) (*model.FORC, error) {
 		XR: mxr,
 	}, nil
 }
+
+// CatchmentForcings returns the first npar forcings of frc, summed to the daily dates dts,
+// averaged over cells cids weighted by the number of cells of each met location
+func CatchmentForcings(frc *model.FORC, cids []int, dts []time.Time, npar int) [][]float64 {
+	fmid := func(cids []int) map[int]float64 {
+		mc := make(map[int]int)
+		for _, cid := range cids {
+			if mid, ok := frc.XR[cid]; ok {
+				if _, ok := mc[mid]; ok {
+					mc[mid]++
+				} else {
+					mc[mid] = 1
+				}
+			} // else {
+			// 	log.Fatalf("error finding met IDs")
+			// }
+		}
+		fmc, dnm := make(map[int]float64, len(mc)), 0.
+		for i, v := range mc {
+			fv := float64(v)
+			fmc[i] = fv
+			dnm += fv
+		}
+		for i, v := range fmc {
+			fmc[i] = v / dnm
+		}
+		return fmc
+	}(cids)
+
+	dxr := make(map[time.Time]int, len(dts))
+	for i, t := range dts {
+		dxr[t] = i
+	}
+	vs := make([][]float64, len(dts))
+	// n := make([]float64, len(dts))
+	for i := 0; i < len(dts); i++ {
+		vs[i] = make([]float64, npar)
+	}
+	for i, t := range frc.T {
+		d := mmio.DayDate(t)
+		if ii, ok := dxr[d]; !ok {
+			continue
+		} else {
+			for mid, w := range fmid {
+				for k := 0; k < npar; k++ {
+					vs[ii][k] += frc.D[k][mid][i] * w
+				}
+			}
+			// n[ii]++
+		}
+	}
+	// for ii := range n {
+	// 	for k := 0; k < npar; k++ {
+	// 		vs[ii][k] /= n[ii]
+	// 	}
+	// }
+	return vs
+}
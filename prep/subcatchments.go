@@ -0,0 +1,82 @@
+package prep
+
+import (
+	"sort"
+	"time"
+
+	rr "github.com/maseology/rainrun/models"
+	"github.com/maseology/rdrr/model"
+)
+
+// Subcatchments delineates the nested subcatchments draining to the outlet cells,
+// returning for each outlet its local cells (excluding those of the outlets upstream)
+// and the outlet immediately downstream, -1 if none
+func Subcatchments(t Terrain, outlets []int) (cids map[int][]int, dn map[int]int) {
+	ca := make(map[int]map[int]bool, len(outlets))
+	for _, o := range outlets {
+		ca[o] = make(map[int]bool)
+		for _, c := range t.ContributingAreaIDs(o) {
+			ca[o][c] = true
+		}
+		ca[o][o] = true
+	}
+
+	cids, dn = make(map[int][]int, len(outlets)), make(map[int]int, len(outlets))
+	for _, o := range outlets {
+		// the downstream outlet is the smallest contributing area containing o
+		dn[o] = -1
+		for _, p := range outlets {
+			if p != o && ca[p][o] && (dn[o] < 0 || len(ca[p]) < len(ca[dn[o]])) {
+				dn[o] = p
+			}
+		}
+
+		// local cells exclude the contributing areas of the outlets upstream
+		cids[o] = make([]int, 0, len(ca[o]))
+		for c := range ca[o] {
+			up := false
+			for _, u := range outlets {
+				if u != o && ca[o][u] && ca[u][c] {
+					up = true
+					break
+				}
+			}
+			if !up {
+				cids[o] = append(cids[o], c)
+			}
+		}
+		sort.Ints(cids[o])
+	}
+	return cids, dn
+}
+
+// SubcatchmentNodes returns the network nodes of the subcatchments delineated by Subcatchments, ordered by
+// outlet cell ID, with local areas from cells of area ca [m²] and the first npar daily forcings of frc over
+// dates dts (see CatchmentForcings), followed by an empty observation column. Node models and reaches are
+// left to the caller, before building the rainrun.Network.
+func SubcatchmentNodes(cids map[int][]int, dn map[int]int, ca float64, frc *model.FORC, dts []time.Time, npar int) []rr.Node {
+	outlets := make([]int, 0, len(cids))
+	for o := range cids {
+		outlets = append(outlets, o)
+	}
+	sort.Ints(outlets)
+
+	doy := make([]int, len(dts))
+	for i, t := range dts {
+		doy[i] = t.YearDay()
+	}
+	nodes := make([]rr.Node, len(outlets))
+	for i, o := range outlets {
+		vs := CatchmentForcings(frc, cids[o], dts, npar)
+		for j := range vs {
+			vs[j] = append(vs[j], 0.)
+		}
+		nodes[i] = rr.Node{
+			ID:   o,
+			DS:   &rr.Dataset{FRC: vs, DT: dts, DOY: doy, Ndt: len(dts), Timestep: 86400., Loc: []float64{float64(o)}},
+			Area: ca * float64(len(cids[o])),
+			Dn:   dn[o],
+		}
+	}
+	return nodes
+}
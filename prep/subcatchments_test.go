@@ -0,0 +1,22 @@
+package prep
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubcatchments(t *testing.T) {
+	v := valley{4, 5}
+	lo, up := v.centre(), 2*v.nc+v.centre() // outlets at rows 0 and 2 of the centre column
+	cids, dn := Subcatchments(v, []int{lo, up})
+	if dn[up] != lo || dn[lo] != -1 {
+		t.Errorf("downstream outlets %v, want %d->%d->-1", dn, up, lo)
+	}
+	want := map[int][]int{
+		lo: {0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		up: {10, 11, 12, 13, 14, 15, 16, 17, 18, 19},
+	}
+	if !reflect.DeepEqual(cids, want) {
+		t.Errorf("local cells %v, want %v", cids, want)
+	}
+}
@@ -0,0 +1,59 @@
+package prep
+
+import (
+	"math"
+
+	"github.com/maseology/goHydro/tem"
+)
+
+// Terrain is the digital elevation model from which catchment attributes are derived
+type Terrain interface {
+	ContributingAreaIDs(cid int) []int // IDs of the cells draining to cell cid
+	Surface(cid int) (z, g, a float64) // elevation [m], gradient [rad] and aspect [rad] of cell cid
+}
+
+// TEM adapts a goHydro topographic elevation model to a Terrain
+type TEM struct{ *tem.TEM }
+
+// Surface returns the elevation, gradient and aspect of cell cid
+func (t TEM) Surface(cid int) (z, g, a float64) {
+	c := t.TEC[cid]
+	return c.Z, c.G, c.A
+}
+
+// TopoIndex returns a topographic index ln(a/tanβ) histogram of nbins classes
+// of bin values and area fractions, for the catchment cells cids of cell width cw
+func TopoIndex(t Terrain, cids []int, cw float64, nbins int) (ti, af []float64) {
+	lnati := make([]float64, 0, len(cids))
+	tmn, tmx := math.MaxFloat64, -math.MaxFloat64
+	for _, cid := range cids {
+		n := 1. // upslope cell count, including cid
+		for _, c := range t.ContributingAreaIDs(cid) {
+			if c != cid {
+				n++
+			}
+		}
+		_, g, _ := t.Surface(cid)
+		tanb := math.Max(math.Tan(g), 1e-4)
+		v := math.Log(n * cw / tanb) // a: upslope area per unit contour length [m]
+		lnati = append(lnati, v)
+		tmn, tmx = math.Min(tmn, v), math.Max(tmx, v)
+	}
+
+	ti, af = make([]float64, nbins), make([]float64, nbins)
+	w := (tmx - tmn) / float64(nbins)
+	for i := range ti {
+		ti[i] = tmn + (float64(i)+.5)*w
+	}
+	for _, v := range lnati {
+		i := nbins - 1
+		if w > 0. {
+			i = int(math.Min((v-tmn)/w, float64(nbins-1)))
+		}
+		af[i]++
+	}
+	for i := range af {
+		af[i] /= float64(len(lnati))
+	}
+	return
+}
@@ -0,0 +1,68 @@
+package prep
+
+import (
+	"math"
+	"testing"
+)
+
+// valley is a synthetic nr×nc DEM of unit cells, z = row + |col-centre|/2, whose side cells
+// drain laterally to the centre column, which drains down to the outlet at row 0.
+// Cell IDs are row*nc + col.
+type valley struct{ nr, nc int }
+
+func (v valley) centre() int { return v.nc / 2 }
+
+// dn returns the cell downslope of cid, -1 at the outlet
+func (v valley) dn(cid int) int {
+	r, c := cid/v.nc, cid%v.nc
+	switch {
+	case c < v.centre():
+		return cid + 1
+	case c > v.centre():
+		return cid - 1
+	case r > 0:
+		return cid - v.nc
+	}
+	return -1
+}
+
+func (v valley) ContributingAreaIDs(cid int) []int {
+	var ids []int
+	for c := 0; c < v.nr*v.nc; c++ {
+		for d := c; d >= 0; d = v.dn(d) {
+			if d == cid {
+				ids = append(ids, c)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+func (v valley) Surface(cid int) (z, g, a float64) {
+	r, c := cid/v.nc, cid%v.nc
+	z = float64(r) + math.Abs(float64(c-v.centre()))/2.
+	if c == v.centre() {
+		return z, math.Atan(1.), 0.
+	}
+	return z, math.Atan(.5), 0.
+}
+
+func TestTopoIndex(t *testing.T) {
+	v := valley{4, 5}
+	cids := v.ContributingAreaIDs(v.centre())
+	if len(cids) != 20 {
+		t.Fatalf("catchment of %d cells, want 20", len(cids))
+	}
+	// ln(a/tanβ): edge cells ln(1/.5), inner side cells ln(2/.5), centre cells ln(5k) for k=1..4 rows upslope
+	ti, af := TopoIndex(v, cids, 1., 4)
+	w := (math.Log(20.) - math.Log(2.)) / 4.
+	for i, want := range []float64{.4, .45, .05, .1} {
+		if math.Abs(af[i]-want) > 1e-12 {
+			t.Errorf("af[%d] = %g, want %g", i, af[i], want)
+		}
+		if x := math.Log(2.) + (float64(i)+.5)*w; math.Abs(ti[i]-x) > 1e-12 {
+			t.Errorf("ti[%d] = %g, want %g", i, ti[i], x)
+		}
+	}
+}
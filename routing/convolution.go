@@ -0,0 +1,59 @@
+package routing
+
+import "fmt"
+
+// Convolution routes a series through unit hydrograph ordinates
+type Convolution struct {
+	uh, cv []float64 // ordinates; pending outflow of the timesteps to come
+}
+
+// NewConvolution returns the convolution of unit hydrograph uh
+func NewConvolution(uh []float64) *Convolution {
+	n := len(uh) - 1
+	if n < 0 {
+		n = 0
+	}
+	return &Convolution{uh: uh, cv: make([]float64, n)}
+}
+
+// Update adds x to the convolution, returning the outflow of the timestep
+func (c *Convolution) Update(x float64) float64 {
+	n := len(c.cv) - 1
+	if n == -1 {
+		if len(c.uh) == 0 {
+			return x
+		}
+		return c.uh[0] * x
+	}
+	q := c.uh[0]*x + c.cv[0]
+	for i := 0; i < n; i++ {
+		c.cv[i] = c.uh[i+1]*x + c.cv[i+1]
+	}
+	c.cv[n] = c.uh[n+1] * x
+	return q
+}
+
+// Storage returns the volume pending release
+func (c *Convolution) Storage() float64 {
+	s := 0.
+	for _, v := range c.cv {
+		s += v
+	}
+	return s
+}
+
+// Pending returns a copy of the outflow pending release
+func (c *Convolution) Pending() []float64 {
+	v := make([]float64, len(c.cv))
+	copy(v, c.cv)
+	return v
+}
+
+// SetPending restores the outflow pending release, as returned by Pending
+func (c *Convolution) SetPending(v []float64) error {
+	if len(v) != len(c.cv) {
+		return fmt.Errorf("routing.SetPending error: expecting length %d, got %d", len(c.cv), len(v))
+	}
+	copy(c.cv, v)
+	return nil
+}
@@ -0,0 +1,54 @@
+package routing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvolution(t *testing.T) {
+	uh := []float64{.2, .5, .3}
+	for _, c := range []struct {
+		name string
+		x    []float64
+		want []float64
+	}{
+		{"impulse", []float64{1., 0., 0., 0.}, []float64{.2, .5, .3, 0.}},
+		{"superposition", []float64{1., 2., 0., 0.}, []float64{.2, .9, 1.3, .6}},
+	} {
+		cv, in, out := NewConvolution(uh), 0., 0.
+		for i, x := range c.x {
+			q := cv.Update(x)
+			if math.Abs(q-c.want[i]) > 1e-12 {
+				t.Errorf("%s: step %d outflow %g, want %g", c.name, i, q, c.want[i])
+			}
+			in += x
+			out += q
+			if math.Abs(in-out-cv.Storage()) > 1e-12 {
+				t.Errorf("%s: step %d volume not conserved", c.name, i)
+			}
+		}
+	}
+
+	if q := NewConvolution(nil).Update(2.); q != 2. {
+		t.Errorf("empty unit hydrograph: got %g, want the inflow", q)
+	}
+	if q := NewConvolution([]float64{1.}).Update(2.); q != 2. {
+		t.Errorf("single ordinate: got %g, want the inflow", q)
+	}
+}
+
+func TestConvolutionPending(t *testing.T) {
+	a, b := NewConvolution([]float64{.2, .5, .3}), NewConvolution([]float64{.2, .5, .3})
+	a.Update(1.)
+	if err := b.SetPending(a.Pending()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if qa, qb := a.Update(0.), b.Update(0.); qa != qb {
+			t.Errorf("step %d: got %g, want %g", i, qb, qa)
+		}
+	}
+	if err := b.SetPending([]float64{1.}); err == nil {
+		t.Error("expected an error for pending outflow of the wrong length")
+	}
+}
@@ -0,0 +1,90 @@
+package routing
+
+import (
+	"fmt"
+	"math"
+)
+
+// Muskingum channel reach routing of discharge [m³/s], sub-stepped such that the
+// routing coefficients remain non-negative: 2KX <= dt <= 2K(1-X)
+// McCarthy, G.T., 1938. The unit hydrograph and flood routing. Conference of North Atlantic Division, US Army Corps of Engineers.
+type Muskingum struct {
+	K, X       float64 // storage constant [s] and weighting factor [0,0.5]
+	c0, c1, c2 float64
+	nsub       int
+	ts         float64
+	i0, o0     float64 // inflow and outflow at the end of the last timestep
+}
+
+// NewMuskingum returns a reach of storage constant k [s] and weighting factor x, routed at timestep ts [s].
+// An error is returned when no whole number of sub-steps satisfies 2KX <= dt <= 2K(1-X).
+func NewMuskingum(k, x, ts float64) (*Muskingum, error) {
+	if k < 0. || x < 0. || x > .5 || ts <= 0. {
+		return nil, fmt.Errorf("Muskingum input error: k must be >= 0, x must be [0,0.5], ts > 0, got %g %g %g", k, x, ts)
+	}
+	m := Muskingum{K: k, X: x, nsub: 1, ts: ts}
+	if k > 0. {
+		m.nsub = int(math.Max(1., math.Ceil(ts/(2.*k*(1.-x))))) // fewest sub-steps with dt <= 2K(1-X)
+		if ts/float64(m.nsub) < 2.*k*x {
+			return nil, fmt.Errorf("Muskingum input error: no sub-step of timestep %g satisfies 2KX <= dt <= 2K(1-X), got k=%g x=%g", ts, k, x)
+		}
+	}
+	dt := ts / float64(m.nsub)
+	d := 2.*k*(1.-x) + dt
+	m.c0 = (dt - 2.*k*x) / d
+	m.c1 = (dt + 2.*k*x) / d
+	m.c2 = (2.*k*(1.-x) - dt) / d
+	return &m, nil
+}
+
+// NewMuskingumCunge returns a Muskingum reach of parameters derived from the channel: length l [m],
+// bed slope s0, Manning's roughness n and width b [m] of a wide rectangular section, at reference discharge qref [m³/s]
+// Cunge, J.A., 1969. On the subject of a flood propagation computation method (Muskingum method). Journal of Hydraulic Research 7(2). pp. 205-230.
+func NewMuskingumCunge(l, s0, n, b, qref, ts float64) (*Muskingum, error) {
+	if l <= 0. || s0 <= 0. || n <= 0. || b <= 0. || qref <= 0. {
+		return nil, fmt.Errorf("MuskingumCunge input error: l, s0, n, b and qref must be > 0, got %g %g %g %g %g", l, s0, n, b, qref)
+	}
+	y := math.Pow(n*qref/(b*math.Sqrt(s0)), .6) // normal depth
+	c := 5. / 3. * qref / (b * y)               // kinematic wave celerity
+	k := l / c
+	x := math.Min(math.Max(.5*(1.-qref/(b*s0*c*l)), 0.), .5)
+	return NewMuskingum(k, x, ts)
+}
+
+// Update routes the mean inflow qin of a timestep, returning the mean outflow
+func (m *Muskingum) Update(qin float64) float64 {
+	if m.K == 0. {
+		m.i0, m.o0 = qin, qin
+		return qin
+	}
+	s0 := m.Storage()
+	for i := 0; i < m.nsub; i++ {
+		m.o0 = m.c0*qin + m.c1*m.i0 + m.c2*m.o0
+		m.i0 = qin
+	}
+	return qin - (m.Storage()-s0)/m.ts // mean outflow, conserving the inflow volume
+}
+
+// Storage returns the reach storage, K[X·I + (1-X)·O] [m³]
+func (m *Muskingum) Storage() float64 {
+	return m.K * (m.X*m.i0 + (1.-m.X)*m.o0)
+}
+
+// Timestep returns the routing timestep [s]
+func (m *Muskingum) Timestep() float64 {
+	return m.ts
+}
+
+// State returns the inflow and outflow at the end of the last timestep
+func (m *Muskingum) State() []float64 {
+	return []float64{m.i0, m.o0}
+}
+
+// SetState restores a state returned by State
+func (m *Muskingum) SetState(s []float64) error {
+	if len(s) != 2 {
+		return fmt.Errorf("Muskingum SetState error: expecting 2 values, got %d", len(s))
+	}
+	m.i0, m.o0 = s[0], s[1]
+	return nil
+}
@@ -0,0 +1,100 @@
+package routing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMuskingum(t *testing.T) {
+	const ts = 86400.
+	for _, c := range []struct {
+		name string
+		k, x float64
+		nsub int
+	}{
+		{"pass-through", 0., .2, 1},
+		{"linear reservoir", 3600., 0., 12},
+		{"long reach", 2. * ts, .2, 1},
+		{"attenuating", 3600., .2, 15},
+		{"x close to 0.5", 3600., .49, 24},
+		{"x of 0.5", 3600., .5, 24},
+	} {
+		m, err := NewMuskingum(c.k, c.x, ts)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if m.nsub != c.nsub {
+			t.Errorf("%s: got %d sub-steps, want %d", c.name, m.nsub, c.nsub)
+		}
+		if dt := ts / float64(m.nsub); c.k > 0. && (dt < 2.*c.k*c.x-1e-9 || dt > 2.*c.k*(1.-c.x)+1e-9) {
+			t.Errorf("%s: sub-step %g outside [2KX, 2K(1-X)]", c.name, dt)
+		}
+		if c.k > 0. && (m.c0 < -1e-12 || m.c1 < 0. || m.c2 < -1e-12 || math.Abs(m.c0+m.c1+m.c2-1.) > 1e-12) {
+			t.Errorf("%s: coefficients %g %g %g", c.name, m.c0, m.c1, m.c2)
+		}
+
+		// a flood wave followed by recession: outflow remains non-negative and the volume is conserved
+		vin, vout := 0., 0.
+		for i := 0; i < 60; i++ {
+			qin := 0.
+			if i < 5 {
+				qin = 10. * math.Sin(math.Pi*float64(i+1)/6.)
+			}
+			q := m.Update(qin)
+			if q < -1e-9 {
+				t.Fatalf("%s: negative outflow %g at step %d", c.name, q, i)
+			}
+			vin += qin * ts
+			vout += q * ts
+			if math.Abs(vin-vout-m.Storage()) > 1e-6 {
+				t.Fatalf("%s: step %d volume not conserved: in %g, out %g, storage %g", c.name, i, vin, vout, m.Storage())
+			}
+		}
+		if m.Storage() > 1e-6*vin {
+			t.Errorf("%s: reach holding %g m³ after recession", c.name, m.Storage())
+		}
+	}
+}
+
+func TestMuskingumSteady(t *testing.T) {
+	m, err := NewMuskingum(3600., .3, 3600.)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var q float64
+	for i := 0; i < 200; i++ {
+		q = m.Update(5.)
+	}
+	if math.Abs(q-5.) > 1e-9 || math.Abs(m.Storage()-3600.*5.) > 1e-6 {
+		t.Errorf("steady state: outflow %g, storage %g; want 5 and %g", q, m.Storage(), 3600.*5.)
+	}
+}
+
+func TestMuskingumErrors(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		k, x, ts float64
+	}{
+		{"negative k", -1., .2, 86400.},
+		{"x above 0.5", 3600., .6, 86400.},
+		{"no whole sub-step with x close to 0.5", 5200., .49, 86400.},
+		{"timestep shorter than 2KX", 86400., .4, 3600.},
+	} {
+		if _, err := NewMuskingum(c.k, c.x, c.ts); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
+
+func TestMuskingumCunge(t *testing.T) {
+	l, s0, n, b, qref := 5000., .001, .035, 20., 10.
+	m, err := NewMuskingumCunge(l, s0, n, b, qref, 86400.)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := math.Pow(n*qref/(b*math.Sqrt(s0)), .6)
+	c := 5. / 3. * qref / (b * y)
+	if math.Abs(m.K-l/c) > 1e-9 || math.Abs(m.X-(.5*(1.-qref/(b*s0*c*l)))) > 1e-12 {
+		t.Errorf("got K=%g X=%g, want K=%g X=%g", m.K, m.X, l/c, .5*(1.-qref/(b*s0*c*l)))
+	}
+}
@@ -0,0 +1,189 @@
+// Package routing provides unit hydrographs and the discrete convolution used to route runoff.
+// Unit hydrographs are returned as ordinates summing to unity, with times given in timesteps.
+package routing
+
+import "math"
+
+const (
+	tail = 1e-6  // ordinates of unbounded unit hydrographs are truncated once their S-curve reaches 1-tail
+	nmax = 10000 // maximum number of ordinates
+)
+
+// fromS returns the n ordinates of S-curve s, the last ordinate closing the unit volume
+func fromS(s func(t float64) float64, n int) []float64 {
+	if n < 1 {
+		n = 1
+	}
+	uh := make([]float64, n)
+	for t := 0; t < n-1; t++ {
+		uh[t] = s(float64(t+1)) - s(float64(t))
+	}
+	uh[n-1] = 1. - s(float64(n-1))
+	return uh
+}
+
+// untilTail returns the number of ordinates needed for S-curve s to reach 1-tail
+func untilTail(s func(t float64) float64) int {
+	n := 1
+	for n < nmax && s(float64(n)) < 1.-tail {
+		n++
+	}
+	return n
+}
+
+// SH1 returns the GR4J unit hydrograph UH1 of time base x4
+// Perrin C., C. Michel, V. Andreassian, 2003. Improvement of a parsimonious model for streamflow simulation. Journal of Hydrology 279. pp. 275-289.
+func SH1(x4 float64) []float64 {
+	return fromS(func(t float64) float64 {
+		if t < x4 {
+			return math.Pow(t/x4, 2.5)
+		}
+		return 1.
+	}, int(math.Ceil(x4)))
+}
+
+// SH2 returns the GR4J unit hydrograph UH2 of time base 2·x4
+func SH2(x4 float64) []float64 {
+	return fromS(func(t float64) float64 {
+		if t <= x4 {
+			return math.Pow(t/x4, 2.5) / 2.
+		} else if t < 2.*x4 {
+			return 1. - math.Pow(2.-t/x4, 2.5)/2.
+		}
+		return 1.
+	}, int(math.Ceil(2.*x4)))
+}
+
+// Triangular returns the symmetric triangular unit hydrograph of time base maxbas (HBV)
+// Seibert, J., 2005. HBV light version 2, user's manual. Uppsala University.
+func Triangular(maxbas float64) []float64 {
+	return fromS(func(t float64) float64 {
+		if t <= maxbas/2. {
+			return 2. * math.Pow(t/maxbas, 2.)
+		} else if t < maxbas {
+			return 1. - 2.*math.Pow(1.-t/maxbas, 2.)
+		}
+		return 1.
+	}, int(math.Ceil(maxbas)))
+}
+
+// Gamma returns the gamma-distributed unit hydrograph of shape a and scale theta
+func Gamma(a, theta float64) []float64 {
+	s := func(t float64) float64 { return gammp(a, t/theta) }
+	return fromS(s, untilTail(s))
+}
+
+// Nash returns the unit hydrograph of a cascade of n linear reservoirs of storage constant k
+// Nash, J.E., 1957. The form of the instantaneous unit hydrograph. IAHS Publication 45(3). pp. 114-121.
+func Nash(n int, k float64) []float64 {
+	return Gamma(float64(n), k)
+}
+
+// Clark returns the Clark unit hydrograph of time of concentration tc and storage coefficient r,
+// a HEC-1 synthetic time-area curve routed through a linear reservoir
+// Clark, C.O., 1945. Storage and the unit hydrograph. Transactions of the ASCE 110. pp. 1419-1446.
+func Clark(tc, r float64) []float64 {
+	ta := func(t float64) float64 { // cumulative time-area
+		switch x := t / tc; {
+		case x >= 1.:
+			return 1.
+		case x <= .5:
+			return 1.414 * math.Pow(x, 1.5)
+		default:
+			return 1. - 1.414*math.Pow(1.-x, 1.5)
+		}
+	}
+	k := 1.
+	if r > 0. {
+		k = 1. - math.Exp(-1./r)
+	}
+	uh, s, c := make([]float64, 0), 0., 0.
+	for t := 0; t < nmax; t++ {
+		s += ta(float64(t+1)) - ta(float64(t))
+		o := k * s
+		s -= o
+		uh = append(uh, o)
+		if c += o; float64(t+1) >= tc && c >= 1.-tail {
+			break
+		}
+	}
+	uh[len(uh)-1] += 1. - c
+	return uh
+}
+
+// scs dimensionless unit hydrograph (t/tp, q/qp)
+var scs = [][2]float64{
+	{0., 0.}, {.1, .03}, {.2, .1}, {.3, .19}, {.4, .31}, {.5, .47}, {.6, .66}, {.7, .82}, {.8, .93}, {.9, .99},
+	{1., 1.}, {1.1, .99}, {1.2, .93}, {1.3, .86}, {1.4, .78}, {1.5, .68}, {1.6, .56}, {1.7, .46}, {1.8, .39}, {1.9, .33},
+	{2., .28}, {2.2, .207}, {2.4, .147}, {2.6, .107}, {2.8, .077}, {3., .055}, {3.2, .04}, {3.4, .029}, {3.6, .021},
+	{3.8, .015}, {4., .011}, {4.5, .005}, {5., 0.},
+}
+
+// SCS returns the NRCS (SCS) dimensionless unit hydrograph of time to peak tp
+// USDA-NRCS, 2007. National Engineering Handbook, Part 630 Hydrology, Chapter 16: Hydrographs.
+func SCS(tp float64) []float64 {
+	// piecewise-linear S-curve, normalized to unit area
+	a := make([]float64, len(scs))
+	for i := 1; i < len(scs); i++ {
+		a[i] = a[i-1] + (scs[i][0]-scs[i-1][0])*(scs[i][1]+scs[i-1][1])/2.
+	}
+	tot := a[len(a)-1]
+	s := func(t float64) float64 {
+		x := t / tp
+		for i := 1; i < len(scs); i++ {
+			if x < scs[i][0] {
+				dx := x - scs[i-1][0]
+				qx := scs[i-1][1] + (scs[i][1]-scs[i-1][1])*dx/(scs[i][0]-scs[i-1][0])
+				return (a[i-1] + dx*(scs[i-1][1]+qx)/2.) / tot
+			}
+		}
+		return 1.
+	}
+	return fromS(s, int(math.Ceil(5.*tp)))
+}
+
+// gammp returns the regularized lower incomplete gamma function P(a,x)
+// Press, W.H., S.A. Teukolsky, W.T. Vetterling, B.P. Flannery, 1992. Numerical Recipes in C, 2nd ed. pp. 216-219.
+func gammp(a, x float64) float64 {
+	if x <= 0. {
+		return 0.
+	}
+	lg, _ := math.Lgamma(a)
+	if x < a+1. { // series representation
+		ap, del := a, 1./a
+		sum := del
+		for n := 0; n < 500; n++ {
+			ap++
+			del *= x / ap
+			sum += del
+			if math.Abs(del) < math.Abs(sum)*1e-14 {
+				break
+			}
+		}
+		return sum * math.Exp(-x+a*math.Log(x)-lg)
+	}
+	// continued fraction representation (modified Lentz)
+	const fpmin = 1e-300
+	b := x + 1. - a
+	c, d := 1./fpmin, 1./b
+	h := d
+	for i := 1; i < 500; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2.
+		d = an*d + b
+		if math.Abs(d) < fpmin {
+			d = fpmin
+		}
+		c = b + an/c
+		if math.Abs(c) < fpmin {
+			c = fpmin
+		}
+		d = 1. / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1.) < 1e-14 {
+			break
+		}
+	}
+	return 1. - math.Exp(-x+a*math.Log(x)-lg)*h
+}
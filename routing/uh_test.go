@@ -0,0 +1,63 @@
+package routing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnitHydrographs(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		uh   []float64
+		want []float64 // leading ordinates, nil to check unit volume only
+	}{
+		{"SH1", SH1(2.), []float64{math.Pow(.5, 2.5), 1. - math.Pow(.5, 2.5)}},
+		{"SH1 fractional", SH1(1.5), []float64{math.Pow(1./1.5, 2.5), 1. - math.Pow(1./1.5, 2.5)}},
+		{"SH2", SH2(1.), []float64{.5, .5}},
+		{"Triangular", Triangular(2.), []float64{.5, .5}},
+		{"Triangular 4", Triangular(4.), []float64{.125, .375, .375, .125}},
+		{"Nash single reservoir", Nash(1, 2.), []float64{1. - math.Exp(-.5), math.Exp(-.5) - math.Exp(-1.)}},
+		{"Nash", Nash(3, 1.5), nil},
+		{"Gamma", Gamma(2.5, .7), nil},
+		{"Gamma sub-step", Gamma(1., .01), []float64{1.}},
+		{"Clark", Clark(3., 2.), nil},
+		{"Clark without storage", Clark(2., 0.), []float64{1.414 * math.Pow(.5, 1.5), 1. - 1.414*math.Pow(.5, 1.5)}},
+		{"SCS", SCS(2.), nil},
+	} {
+		s := 0.
+		for _, v := range c.uh {
+			if v < 0. {
+				t.Errorf("%s: negative ordinate in %v", c.name, c.uh)
+				break
+			}
+			s += v
+		}
+		if math.Abs(s-1.) > 1e-12 {
+			t.Errorf("%s: ordinates sum to %.15f", c.name, s)
+		}
+		if len(c.uh) < len(c.want) {
+			t.Errorf("%s: got %d ordinates, want at least %d", c.name, len(c.uh), len(c.want))
+			continue
+		}
+		for i, v := range c.want {
+			if math.Abs(c.uh[i]-v) > 1e-9 {
+				t.Errorf("%s: got %v, want %v", c.name, c.uh, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestGammp(t *testing.T) {
+	for _, c := range []struct{ a, x, want float64 }{
+		{1., 2., 1. - math.Exp(-2.)},       // exponential
+		{2., 1.5, 1. - 2.5*math.Exp(-1.5)}, // Erlang, series representation
+		{2., 4., 1. - 5.*math.Exp(-4.)},    // Erlang, continued fraction
+		{.5, 2., math.Erf(math.Sqrt(2.))},  // half-integer shape
+		{3., 0., 0.},
+	} {
+		if got := gammp(c.a, c.x); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("P(%g,%g) = %.15f, want %.15f", c.a, c.x, got, c.want)
+		}
+	}
+}
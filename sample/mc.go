@@ -1,49 +1,38 @@
 package sample
 
 import (
-	"log"
 	"math"
-	"math/rand"
-	"time"
 
-	"github.com/maseology/UTM"
-	"github.com/maseology/goHydro/solirrad"
 	"github.com/maseology/montecarlo"
-	mrg63k3a "github.com/maseology/pnrg/MRG63k3a"
 	rr "github.com/maseology/rainrun/models"
 )
 
 // Sample samples a rainrun model
-func Sample(metfp string, nsmpl int, fitness func(o, s []float64) float64) ([][]float64, []float64) {
-	rr.LoadMET(metfp, false)
+func Sample(ds *rr.Dataset, nsmpl int, fitness func(o, s []float64) float64) ([][]float64, []float64) {
+	e, _ := rr.Lookup("MakkinkCCFGR4J")
 
-	lat, _, err := UTM.ToLatLon(rr.Loc[1], rr.Loc[2], 17, "", true)
-	if err != nil {
-		log.Fatalf("%v", err)
+	obs := make([]float64, ds.Ndt)
+	for i := range ds.FRC {
+		obs[i] = ds.Obs(i) // [m/d]
 	}
-	si := solirrad.New(lat, math.Tan(rr.Loc[4]), rr.Loc[5])
 
-	obs := make([]float64, rr.Ndt)
-	for i, v := range rr.FRC {
-		obs[i] = v[4] // [m/d]
-	}
-
-	rng := rand.New(mrg63k3a.New())
-	rng.Seed(time.Now().UnixNano())
-
-	ndim := 10
 	gen := func(u []float64) float64 {
-		var m rr.MakkinkCCFGR4J
-		m.New(MakkinkCCFGR4J(u)...)
-		m.SI = &si
+		m := e.New()
+		if err := m.New(ds, e.Sample(u)...); err != nil {
+			return -9999.
+		}
 
 		f := func(obs []float64) float64 {
-			sim := make([]float64, rr.Ndt)
-			for i, v := range rr.FRC {
-				_, _, r, _ := m.Update(v, rr.DOY[i])
+			sim := make([]float64, ds.Ndt)
+			for i := range ds.FRC {
+				_, _, r, _ := rr.Step(ds, m, i)
 				sim[i] = r
 			}
-			return fitness(obs[365:], sim[365:])
+			if rr.UpdateError(m) != nil {
+				return -9999.
+			}
+			w := ds.Warmup()
+			return fitness(obs[w:], sim[w:])
 		}(obs)
 		if math.IsNaN(f) {
 			// log.Fatalf("Objective function error, u: %v\n", u)
@@ -52,5 +41,5 @@ func Sample(metfp string, nsmpl int, fitness func(o, s []float64) float64) ([][]
 		return f
 	}
 
-	return montecarlo.GenerateSamples(gen, ndim, nsmpl)
+	return montecarlo.GenerateSamples(gen, e.Ndim, nsmpl)
 }
@@ -1,41 +1,27 @@
 package sample
 
 import (
-	mm "github.com/maseology/mmaths"
 	"github.com/maseology/montecarlo/jointdist"
+	rr "github.com/maseology/rainrun/models"
 )
 
-const (
-	soildepth = 1.
-	n         = 0.3
-	fc        = 0.1
-)
+// transform maps a uniform sample u onto the default sampling ranges of parameters ps
+func transform(ps []rr.Parameter, u []float64) []float64 {
+	p := make([]float64, len(ps))
+	for i, par := range ps {
+		p[i] = par.Sample(u[i])
+	}
+	return p
+}
 
 // Makkink (2)
 func Makkink(u []float64) []float64 {
-	alpha := mm.LinearTransform(0., 2.5, u[0])
-	beta := mm.LinearTransform(-.01, .003, u[1])
-	return []float64{alpha, beta}
-	// smps := make([]*sampler.Sampler, 2)
-	// smps[0] = sampler.New("alpha", sampler.Linear, 0., 2.5)
-	// smps[1] = sampler.New("beta", sampler.Linear, -.01, .003)
-	// return smps
+	return transform(rr.MakkinkParameters(), u)
 }
 
-// CCF (4)
+// CCF (5)
 func CCF(u []float64) []float64 {
-	tindex := mm.LogLinearTransform(.0002, .05, u[0])
-	ddfc := mm.LinearTransform(0., 10., u[1])
-	baseT := mm.LinearTransform(-5., 5., u[2])
-	tsf := mm.LinearTransform(.1, .7, u[3])
-	return []float64{tindex, ddfc, baseT, tsf}
-	// smps := make([]*sampler.Sampler, 4)
-	// smps[0] = sampler.New("tindex", sampler.LogLinear, .0002, .05) // CCF temperature index; range .0002 to 0.0005 m/°C/d -- roughly 1/10 DDF (pg.278)
-	// smps[1] = sampler.New("ddfc", sampler.Linear, 0., 10.)         // DDF adjustment factor based on pack density, see DeWalle and Rango, pg. 275; Ref: Martinec (1960)=1.1
-	// smps[2] = sampler.New("baseT", sampler.Linear, -5., 5.)        // base/critical temperature (°C)
-	// smps[3] = sampler.New("tsf", sampler.Linear, .1, .7)           // TSF (surface temperature factor), 0.1-0.5 have been used
-	// // ddf := mm.LinearTransform(0.001, 0.008, u[1])               // (initial) degree-day/melt factor; range .001 to .008 m/°C/d  (pg.275)
-	// return smps
+	return transform(rr.CCFParameters(), u)
 }
 
 ////////////////
@@ -44,151 +30,161 @@ func CCF(u []float64) []float64 {
 
 // Atkinson (7)
 func Atkinson(u []float64) []float64 {
-	x1 := mm.LinearTransform(0., soildepth*fc, u[1])     // threshold storage (sfc=D(fc-tr))
-	x0 := x1 + mm.LinearTransform(0., soildepth*n, u[0]) // watershed storage (sbc=D(n-tr))
-	x2 := mm.LinearTransform(0., 1., u[2])               // coverdense
-	x3 := mm.LinearTransform(0., 0.01, u[3])             // intcap
-	x4 := mm.LogLinearTransform(0.00001, 1., u[4])       // kb
-	x5 := mm.LinearTransform(0., 100., u[5])             // a
-	x6 := mm.LinearTransform(0., 1., u[6])               // b
-	return []float64{x0, x1, x2, x3, x4, x5, x6}
+	ps := (&rr.Atkinson{}).Parameters()
+	p := transform(ps, u)
+	p[0] += p[1] // watershed storage (sbc=D(n-tr)) sampled as an excess over threshold storage (sfc=D(fc-tr))
+	return p
+}
+
+// ABCD (4)
+func ABCD(u []float64) []float64 {
+	return transform((&rr.ABCD{}).Parameters(), u)
+}
+
+// AWBM (8)
+func AWBM(u []float64) []float64 {
+	return transform((&rr.AWBM{}).Parameters(), u)
 }
 
 // DawdyODonnell (6)
-func DawdyODonnell(u []float64, ts float64) []float64 {
-	ksat := mm.LogLinearTransform(1e-12, 1., u[0]) * ts // ksat [m/ts]
-	rs := mm.LinearTransform(0., 0.1, u[1])             // depression and interception capacity R*
-	ms := mm.LinearTransform(0., 1000., u[2])           // upper soil zone capacity M*
-	gs := mm.LinearTransform(0., 1000., u[3])           // lower soil zone capacity G*
-	s := mm.LogLinearTransform(1e-5, 1., u[4])          // overland flow recession coefficient
-	b := mm.LogLinearTransform(1e-5, 1., u[5])          // baseflow recession coefficient
-	return []float64{ksat, rs, ms, gs, s, b}
+func DawdyODonnell(u []float64) []float64 {
+	return transform((&rr.DawdyODonnell{}).Parameters(), u)
+}
+
+// GR2M (2) monthly
+func GR2M(u []float64) []float64 {
+	return transform((&rr.GR2M{}).Parameters(), u)
 }
 
 // GR4J (4) with iterative warmup to Q0
 func GR4J(u []float64) []float64 {
-	x1 := mm.LinearTransform(0., 2., u[0])  // x1: "production storage" capacity (m)
-	x2 := mm.LinearTransform(-1., 1., u[1]) // x2: water exchange coefficient (>0 for water imports, <0 for exports, =0 for no exchange)
-	x3 := mm.LinearTransform(0., 25., u[2]) // x3: "routing storage"/groundwater storage capacity (m)
-	x4 := mm.LinearTransform(.5, 10., u[3]) // x4: unit hydrograph time base (days)
-	// qsplt := mm.LinearTransform(.5, 1., u[4]) // fixed in paper as 0.9
-	return []float64{x1, x2, x3, x4}
-	// smps := make([]*sampler.Sampler, 4)
-	// smps[0] = sampler.New("x1", sampler.Linear, 0., 1.)  // x1: "production storage" capacity (mm)
-	// smps[1] = sampler.New("x2", sampler.Linear, -.1, .1) // x2: water exchange coefficient (>0 for water imports, <0 for exports, =0 for no exchange)
-	// smps[2] = sampler.New("x3", sampler.Linear, 0., 3.)  // x3: "routing storage"/groundwater storage capacity (mm)
-	// smps[3] = sampler.New("x4", sampler.Linear, .5, 10.) // x4: unit hydrograph time base (days)
-	// return smps
-}
-
-// CCFGR4J (8)
+	return transform((&rr.GR4J{}).Parameters(), u)
+}
+
+// GR5J (5)
+func GR5J(u []float64) []float64 {
+	return transform((&rr.GR5J{}).Parameters(), u)
+}
+
+// GR6J (6)
+func GR6J(u []float64) []float64 {
+	return transform((&rr.GR6J{}).Parameters(), u)
+}
+
+// CCFGR4J (9)
 func CCFGR4J(u []float64) []float64 {
 	ugr4j := GR4J(u)
 	uccf := CCF(u[4:])
 	return append(ugr4j, uccf...)
 }
 
-// MakkinkCCFGR4J (10)
+// MakkinkCCFGR4J (11)
 func MakkinkCCFGR4J(u []float64) []float64 {
 	ugr4j := GR4J(u)
 	uccf := CCF(u[4:])
-	mak := Makkink(u[8:])
+	mak := Makkink(u[9:])
 	return append(ugr4j, append(uccf, mak...)...)
 }
 
 // HBV (9)
-func HBV(u []float64, ts float64) []float64 {
-	fc := mm.LinearTransform(0., 10., u[0])
-	lp := mm.LinearTransform(0., 1., u[1])
-	beta := mm.LinearTransform(0., 10., u[2])
-	uzl := mm.LinearTransform(0., 100., u[3]) // upper zone fast flow limit
-	k0 := mm.LinearTransform(0., 1., u[4])
-	k1 := mm.LinearTransform(0., 1., u[5])
-	k2 := mm.LinearTransform(0., 1., u[6])
-	perc := mm.LogLinearTransform(1e-12, 1., u[7]) * ts // ksat [m/d]
-	maxbas := mm.LinearTransform(0., 10., u[8])         // days
-	// lakefrac := mm.LinearTransform(0., 1., u[9])
-	return []float64{fc, lp, beta, uzl, k0, k1, k2, perc, maxbas} //, lakefrac}
-}
-
-// CCFHBV (13)
-func CCFHBV(u []float64, ts float64) []float64 {
-	uhbv := HBV(u, ts)
+func HBV(u []float64) []float64 {
+	return transform((&rr.HBV{}).Parameters(), u)
+}
+
+// CCFHBV (14)
+func CCFHBV(u []float64) []float64 {
+	uhbv := HBV(u)
 	uccf := CCF(u[9:])
-	// tindex := mm.LogLinearTransform(0.0002, 0.05, u[9]) // CCF temperature index; range .0002 to 0.0005 m/°C/d -- roughly 1/10 DDF (pg.278)
-	// ddfc := mm.LinearTransform(.01, 2.5, u[10])         // DDF adjustment factor based on pack density, see DeWalle and Rango, pg. 275; Ref: Martinec (1960)=1.1
-	// baseT := mm.LinearTransform(-5., 5., u[11])         // base/critical temperature (°C)
-	// tsf := mm.LinearTransform(0.1, 0.6, u[12])          // TSF (surface temperature factor), 0.1-0.5 have been used
-	// // ddf := mm.LinearTransform(0.001, 0.008, u[1])           // (initial) degree-day/melt factor; range .001 to .008 m/°C/d  (pg.275)
-	// uccf := []float64{tindex, ddfc, baseT, tsf}
 	return append(uhbv, uccf...)
 }
 
-// MakkinkCCFHBV (15)
-func MakkinkCCFHBV(u []float64, ts float64) []float64 {
-	uhbv := HBV(u, ts)
+// MakkinkCCFHBV (16)
+func MakkinkCCFHBV(u []float64) []float64 {
+	uhbv := HBV(u)
 	uccf := CCF(u[9:])
-	mak := Makkink(u[13:])
+	mak := Makkink(u[14:])
 	return append(uhbv, append(uccf, mak...)...)
 }
 
+// HYMOD (5)
+func HYMOD(u []float64) []float64 {
+	return transform((&rr.HYMOD{}).Parameters(), u)
+}
+
+// IHACRES (6)
+func IHACRES(u []float64) []float64 {
+	return transform((&rr.IHACRES{}).Parameters(), u)
+}
+
 // ManabeGW (5)
 func ManabeGW(u []float64) []float64 {
+	ps := (&rr.ManabeGW{}).Parameters()
 	u2t, u0t := jointdist.Nested2(u[2], u[0])
-	x0 := mm.LinearTransform(0., soildepth, u0t)
-	x1 := u[1]
-	x2 := mm.LinearTransform(0., soildepth, u2t)
-	x3 := mm.LogLinearTransform(1e-10, 1., u[3])
-	x4 := u[4]
+	x0 := ps[0].Sample(u0t)
+	x1 := ps[1].Sample(u[1])
+	x2 := ps[2].Sample(u2t)
+	x3 := ps[3].Sample(u[3])
+	x4 := ps[4].Sample(u[4])
 	return []float64{x0, x1, x2, x3, x4}
 }
 
 // MultiLayerCapacitance (9)
 func MultiLayerCapacitance(u []float64) []float64 {
-	cv := mm.LinearTransform(0., 1., u[0])
-	x1 := mm.LinearTransform(0., soildepth*1000., u[1])
+	ps := (&rr.MultiLayerCapacitance{}).Parameters()
+	cv := ps[0].Sample(u[0])
+	x1 := ps[1].Sample(u[1])
 	uj0, uj1 := jointdist.Nested2(u[2], u[3])
-	x2 := mm.LinearTransform(0., n, uj0)
-	fc := mm.LinearTransform(0., n, uj1)
-	a := mm.LinearTransform(0., 100., u[4])
-	b := mm.LinearTransform(0., 1., u[5])
+	x2 := ps[2].Sample(uj0)
+	fc := ps[3].Sample(uj1)
+	a := ps[4].Sample(u[4])
+	b := ps[5].Sample(u[5])
 	l := jointdist.SumToOne(u[6], u[7], u[8])
 	return []float64{cv, x1, x2, fc, a, b, l[0], l[1], l[2]}
 }
 
+// PDM (10)
+func PDM(u []float64) []float64 {
+	return transform((&rr.PDM{}).Parameters(), u)
+}
+
 // Quinn (11)
 func Quinn(u []float64) []float64 {
-	intCap := mm.LinearTransform(0., 0.1, u[0])
-	impCap := mm.LinearTransform(0., 0.1, u[1])
-	gwCap := mm.LinearTransform(0., 100., u[2])
-	fImp := mm.LinearTransform(0., 1., u[3])
-	ksat := mm.LogLinearTransform(1e-12, 1., u[4]) // ksat [m/s]
-	rootZoneDepth := mm.LinearTransform(0., soildepth*1000., u[5])
-	porosity := mm.LinearTransform(0., n, u[6])
-	fieldCap := mm.LinearTransform(0., fc, u[7])
-	f := mm.LinearTransform(0., 1., u[8])
-	alpha := mm.LinearTransform(0., 1., u[9])
-	zwt := mm.LinearTransform(0., 10., u[10])
-	return []float64{intCap, impCap, gwCap, fImp, ksat, rootZoneDepth, porosity, fieldCap, f, alpha, zwt}
+	return transform((&rr.Quinn{}).Parameters(), u)
+}
+
+// Sacramento (16)
+func Sacramento(u []float64) []float64 {
+	return transform((&rr.Sacramento{}).Parameters(), u)
+}
+
+// CCFSacramento (21)
+func CCFSacramento(u []float64) []float64 {
+	usac := Sacramento(u)
+	uccf := CCF(u[16:])
+	return append(usac, uccf...)
 }
 
 // SIXPAR (6)
 func SIXPAR(u []float64) []float64 {
-	upCap := mm.LinearTransform(0., 100., u[0])
-	lowCap := mm.LinearTransform(0., 100., u[1])
-	upK := mm.LinearTransform(0., 1., u[2])
-	lowK := mm.LinearTransform(0., 1., u[3])
-	z := mm.LinearTransform(0., 1., u[4])
-	x := mm.LinearTransform(0., 1., u[5])
-	return []float64{upCap, lowCap, upK, lowK, z, x}
+	return transform((&rr.SIXPAR{}).Parameters(), u)
+}
+
+// SIMHYD (7)
+func SIMHYD(u []float64) []float64 {
+	return transform((&rr.SIMHYD{}).Parameters(), u)
 }
 
 // SPLR (5)
 func SPLR(u []float64) []float64 {
-	r12 := mm.LinearTransform(0., 10., u[0])
-	r23 := mm.LinearTransform(0., 1., u[1])
-	k1 := mm.LinearTransform(0., 1., u[2])
-	k2 := mm.LinearTransform(0., 1., u[3])
-	k3 := mm.LinearTransform(0., 1., u[4])
-	return []float64{r12, r23, k1, k2, k3}
+	return transform((&rr.SPLR{}).Parameters(), u)
+}
+
+// TOPMODEL (4)
+func TOPMODEL(u []float64) []float64 {
+	return transform((&rr.TOPMODEL{}).Parameters(), u)
+}
+
+// Xinanjiang (13)
+func Xinanjiang(u []float64) []float64 {
+	return transform((&rr.Xinanjiang{}).Parameters(), u)
 }
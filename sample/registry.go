@@ -0,0 +1,37 @@
+package sample
+
+import rr "github.com/maseology/rainrun/models"
+
+// register adds a built-in model, sampled over its full set of described parameters
+func register(name string, fnew func() rr.Model, smpl func(u []float64) []float64) {
+	ndim := len(fnew().(rr.Describer).Parameters())
+	rr.Register(name, rr.Entry{New: fnew, Sample: smpl, Ndim: ndim})
+}
+
+func init() {
+	register("ABCD", func() rr.Model { return &rr.ABCD{} }, ABCD)
+	register("Atkinson", func() rr.Model { return &rr.Atkinson{} }, Atkinson)
+	register("AWBM", func() rr.Model { return &rr.AWBM{} }, AWBM)
+	register("CCFGR4J", func() rr.Model { return &rr.CCFGR4J{} }, CCFGR4J)
+	register("CCFHBV", func() rr.Model { return &rr.CCFHBV{} }, CCFHBV)
+	register("CCFSacramento", func() rr.Model { return &rr.CCFSacramento{} }, CCFSacramento)
+	register("DawdyODonnell", func() rr.Model { return &rr.DawdyODonnell{} }, DawdyODonnell)
+	register("GR2M", func() rr.Model { return &rr.GR2M{} }, GR2M)
+	register("GR4J", func() rr.Model { return &rr.GR4J{} }, GR4J)
+	register("GR5J", func() rr.Model { return &rr.GR5J{} }, GR5J)
+	register("GR6J", func() rr.Model { return &rr.GR6J{} }, GR6J)
+	register("HBV", func() rr.Model { return &rr.HBV{} }, HBV)
+	register("HYMOD", func() rr.Model { return &rr.HYMOD{} }, HYMOD)
+	register("IHACRES", func() rr.Model { return &rr.IHACRES{} }, IHACRES)
+	register("MakkinkCCFGR4J", func() rr.Model { return &rr.MakkinkCCFGR4J{} }, MakkinkCCFGR4J)
+	register("ManabeGW", func() rr.Model { return &rr.ManabeGW{} }, ManabeGW)
+	register("MultiLayerCapacitance", func() rr.Model { return &rr.MultiLayerCapacitance{} }, MultiLayerCapacitance)
+	register("PDM", func() rr.Model { return &rr.PDM{} }, PDM)
+	register("Quinn", func() rr.Model { return &rr.Quinn{} }, Quinn)
+	register("Sacramento", func() rr.Model { return &rr.Sacramento{} }, Sacramento)
+	register("SIMHYD", func() rr.Model { return &rr.SIMHYD{} }, SIMHYD)
+	register("SIXPAR", func() rr.Model { return &rr.SIXPAR{} }, SIXPAR)
+	register("SPLR", func() rr.Model { return &rr.SPLR{} }, SPLR)
+	register("TOPMODEL", func() rr.Model { return &rr.TOPMODEL{} }, TOPMODEL)
+	register("Xinanjiang", func() rr.Model { return &rr.Xinanjiang{} }, Xinanjiang)
+}